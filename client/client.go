@@ -2,6 +2,7 @@ package client
 
 import (
 	"log"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/mechanical-lich/mlge/ecs"
@@ -35,9 +36,12 @@ type ClientConfig struct {
 //  1. Handles input (via mlge input.InputManager) and forwards events as
 //     Commands to the server via the ClientTransport.
 //  2. Polls for the latest Snapshot from the server.
-//  3. Decodes the snapshot into the local world via the SnapshotCodec.
-//  4. Runs RenderSystems (animations, interpolation) at frame rate.
-//  5. Delegates Update/Draw to the ClientState stack.
+//  3. Decodes the snapshot into the local world via the SnapshotCodec and,
+//     with a Predictor set, replays the commands the server has not yet
+//     simulated.
+//  4. Advances the Predictor, if any, at the server's tick rate.
+//  5. Runs RenderSystems (animations, interpolation) at frame rate.
+//  6. Delegates Update/Draw to the ClientState stack.
 //
 // Create with [NewClient].
 type Client struct {
@@ -46,9 +50,11 @@ type Client struct {
 	inputManager *input.InputManager
 	inputMapper  InputMapper
 	renderSys    RenderSystemManager
+	predictor    *Predictor
 	stateMachine clientStateMachine
 	world        any
 	entitySource func() []*ecs.Entity
+	ecsWorld     *ecs.World // set when entities come from an ecs.World; enables query-driven passes
 	screenW      int
 	screenH      int
 }
@@ -60,6 +66,7 @@ type Client struct {
 //   - initialState: the first ClientState pushed onto the state stack.
 //   - world: the client's local (non-authoritative) copy of the game world.
 //   - entitySource: function returning the local entity slice for RenderSystems.
+//     May be nil when world is an *ecs.World (or implements ecs.WorldProvider).
 //   - cfg: window configuration.
 func NewClient(
 	t transport.ClientTransport,
@@ -76,11 +83,20 @@ func NewClient(
 		ebiten.SetWindowTitle(cfg.WindowTitle)
 	}
 
+	var ecsWorld *ecs.World
+	if entitySource == nil {
+		if w, ok := ecs.WorldFrom(world); ok {
+			ecsWorld = w
+			entitySource = w.Entities
+		}
+	}
+
 	c := &Client{
 		transport:    t,
 		codec:        codec,
 		world:        world,
 		entitySource: entitySource,
+		ecsWorld:     ecsWorld,
 		screenW:      cfg.ScreenWidth,
 		screenH:      cfg.ScreenHeight,
 	}
@@ -98,11 +114,61 @@ func (c *Client) SetInputMapper(m InputMapper) {
 	c.inputMapper = m
 }
 
+// SetPredictor enables client-side prediction with p: commands sent with
+// SendCommand are applied locally at once and replayed on top of each
+// snapshot until the server has simulated them. See [Predictor]. Pass nil to
+// disable prediction. Call after NewClient, before Run.
+func (c *Client) SetPredictor(p *Predictor) {
+	if p != nil {
+		p.bind(c.world, c.ecsWorld, c.entitySource)
+	}
+	c.predictor = p
+}
+
+// SendCommand sends cmd to the server. With a Predictor set, cmd is first
+// stamped with the next prediction tick and kept for local replay, so
+// ClientStates should send commands they want predicted through this method
+// rather than the transport directly.
+func (c *Client) SendCommand(cmd *transport.Command) {
+	if c.predictor != nil {
+		c.predictor.stamp(cmd)
+	}
+	c.transport.SendCommand(cmd)
+}
+
 // AddRenderSystem appends a RenderSystem to the client's render pass.
 func (c *Client) AddRenderSystem(s RenderSystem) {
 	c.renderSys.AddSystem(s)
 }
 
+// AddRenderSystemWithOptions adds a RenderSystem with explicit scheduling
+// options (name, phase, Before/After constraints).
+func (c *Client) AddRenderSystemWithOptions(s RenderSystem, opts ecs.SystemOptions) {
+	c.renderSys.AddSystemWithOptions(s, opts)
+}
+
+// SetRenderSystemEnabled enables or disables the render system registered
+// under name (see RenderSystemManager.SetEnabled).
+func (c *Client) SetRenderSystemEnabled(name string, enabled bool) error {
+	return c.renderSys.SetEnabled(name, enabled)
+}
+
+// SetProfiler records per-system render timings in p (see
+// RenderSystemManager.SetProfiler) along with the wall time of each Update.
+// If p has no Budget, it is set to one Ebitengine tick (1/TPS). Pass nil to
+// stop profiling.
+func (c *Client) SetProfiler(p *ecs.Profiler) {
+	if p != nil && p.Budget == 0 {
+		p.Budget = time.Second / time.Duration(ebiten.TPS())
+	}
+	c.renderSys.SetProfiler(p)
+}
+
+// Profiler returns the profiler set with SetProfiler, or nil.
+func (c *Client) Profiler() *ecs.Profiler {
+	return c.renderSys.Profiler()
+}
+
 // Run starts the Ebitengine window loop. Blocks until the window closes.
 func (c *Client) Run() error {
 	return ebiten.RunGame(c)
@@ -112,6 +178,11 @@ func (c *Client) Run() error {
 
 // Update is called every Ebitengine TPS tick.
 func (c *Client) Update() error {
+	if p := c.renderSys.Profiler(); p != nil {
+		start := time.Now()
+		defer func() { p.RecordTick(time.Since(start)) }()
+	}
+
 	// 1. Poll OS input → mlge events.
 	c.inputManager.HandleInput()
 
@@ -124,18 +195,36 @@ func (c *Client) Update() error {
 		snap = raw
 		// Decode server authority into local world.
 		c.codec.Decode(snap, c.world)
+		// Replay commands the server has not simulated yet.
+		if id, ok := c.transport.(transport.IdentifiedClient); ok && c.predictor != nil {
+			if err := c.predictor.reconcile(snap, id.ClientID()); err != nil {
+				log.Printf("[client] prediction replay error: %v", err)
+			}
+		}
 	}
 
-	// 4. Run render systems (animation, interpolation) at frame rate.
-	entities := c.entitySource()
+	// 4. Advance the prediction at the server's tick rate.
+	if c.predictor != nil {
+		if err := c.predictor.advance(ebiten.TPS()); err != nil {
+			log.Printf("[client] prediction error: %v", err)
+		}
+	}
+
+	// 5. Run render systems (animation, interpolation) at frame rate.
 	if err := c.renderSys.UpdateSystems(c.world); err != nil {
 		log.Printf("[client] RenderSystem global error: %v", err)
 	}
-	if err := c.renderSys.UpdateSystemsForEntities(c.world, entities); err != nil {
+	var err error
+	if c.ecsWorld != nil {
+		err = c.renderSys.UpdateSystemsForWorld(c.world, c.ecsWorld)
+	} else {
+		err = c.renderSys.UpdateSystemsForEntities(c.world, c.entitySource())
+	}
+	if err != nil {
 		log.Printf("[client] RenderSystem entity error: %v", err)
 	}
 
-	// 5. Advance the ClientState machine with the snapshot.
+	// 6. Advance the ClientState machine with the snapshot.
 	c.stateMachine.Update(snap)
 
 	return nil
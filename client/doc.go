@@ -10,6 +10,8 @@
 //   - [RenderSystemManager]: drives RenderSystems each frame.
 //   - [ClientState]: client equivalent of state.StateInterface.
 //     Receives the latest transport.Snapshot on Update; renders in Draw.
+//   - [Predictor]: optionally runs selected SimulationSystems on the client
+//     so the player's own commands take effect before the server confirms them.
 //   - [Client]: implements ebiten.Game. Wires transport, snapshot decode,
 //     render systems, and the client state machine together.
 package client
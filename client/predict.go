@@ -0,0 +1,165 @@
+package client
+
+import (
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/mechanical-lich/mlge/simulation"
+	"github.com/mechanical-lich/mlge/transport"
+)
+
+// pendingCommands is how many unacknowledged commands a Predictor keeps.
+// Once full, the oldest command is dropped and no longer replayed.
+const pendingCommands = 128
+
+// PredictFunc applies a locally issued command to the client's world, the
+// way the server's SimulationState applies it in ProcessCommand. It should
+// only touch state the predicted systems also simulate, such as the position
+// or velocity of the entity the player controls.
+type PredictFunc func(world any, cmd *transport.Command)
+
+// Predictor runs a predicted copy of selected SimulationSystems on the
+// client's world so the player's own commands take effect immediately rather
+// than a round trip later.
+//
+// Commands sent through [Client.SendCommand] are stamped with the predictor's
+// tick, applied locally on that tick and kept until the server reports, in
+// transport.Snapshot.ClientTicks, that it has simulated past them. Each time
+// a snapshot is decoded the world is back at server state; the predictor then
+// replays the ticks the server has not yet simulated, re-applying the pending
+// commands, so the prediction is corrected without losing recent input.
+//
+// Reconciliation needs the client's ID, so the ClientTransport must
+// implement transport.IdentifiedClient, as the local, TCP and delta
+// transports do.
+//
+// Create with [NewPredictor] and install with [Client.SetPredictor].
+type Predictor struct {
+	apply    PredictFunc
+	systems  simulation.SimulationSystemManager
+	tickRate int
+	tick     uint64
+	acc      float64
+
+	// pending is a ring buffer of unacknowledged commands in tick order.
+	pending      [pendingCommands]*transport.Command
+	first, count int
+
+	world        any
+	ecsWorld     *ecs.World
+	entitySource func() []*ecs.Entity
+}
+
+// NewPredictor creates a Predictor that steps its systems tickRate times per
+// second, which should match the server's ServerConfig.TickRate (defaults to
+// 20 if zero). apply may be nil if commands only take effect through the
+// systems themselves.
+func NewPredictor(tickRate int, apply PredictFunc) *Predictor {
+	if tickRate <= 0 {
+		tickRate = 20
+	}
+	return &Predictor{apply: apply, tickRate: tickRate}
+}
+
+// AddSystem registers a SimulationSystem to run predicted on the client.
+// Only add systems that are deterministic given the world and commands,
+// typically movement; the server stays authoritative for everything else.
+func (p *Predictor) AddSystem(sys simulation.SimulationSystem) {
+	p.systems.AddSystem(sys)
+}
+
+// AddSystemWithOptions registers a predicted SimulationSystem with explicit
+// scheduling options (name, phase, Before/After constraints).
+func (p *Predictor) AddSystemWithOptions(sys simulation.SimulationSystem, opts ecs.SystemOptions) {
+	p.systems.AddSystemWithOptions(sys, opts)
+}
+
+// Tick is the current prediction tick.
+func (p *Predictor) Tick() uint64 {
+	return p.tick
+}
+
+// Pending returns the number of commands not yet acknowledged by the server.
+func (p *Predictor) Pending() int {
+	return p.count
+}
+
+// bind sets the world the predicted systems run on.
+func (p *Predictor) bind(world any, ecsWorld *ecs.World, entitySource func() []*ecs.Entity) {
+	p.world = world
+	p.ecsWorld = ecsWorld
+	p.entitySource = entitySource
+}
+
+// stamp marks cmd to take effect on the next prediction tick and keeps it
+// for replay.
+func (p *Predictor) stamp(cmd *transport.Command) {
+	cmd.Tick = p.tick + 1
+	if p.count == pendingCommands {
+		p.first = (p.first + 1) % pendingCommands
+		p.count--
+	}
+	p.pending[(p.first+p.count)%pendingCommands] = cmd
+	p.count++
+}
+
+// advance steps the prediction forward by frames of 1/tps seconds.
+func (p *Predictor) advance(tps int) error {
+	p.acc += float64(p.tickRate) / float64(tps)
+	for p.acc >= 1 {
+		p.acc--
+		p.tick++
+		if err := p.step(p.tick); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcile drops the commands the server has simulated according to snap
+// and replays the rest on top of the freshly decoded world.
+func (p *Predictor) reconcile(snap *transport.Snapshot, id transport.ClientID) error {
+	base, ok := snap.ClientTicks[id]
+	if !ok {
+		return nil
+	}
+	for p.count > 0 && p.pending[p.first].Tick <= base {
+		p.pending[p.first] = nil
+		p.first = (p.first + 1) % pendingCommands
+		p.count--
+	}
+	if base >= p.tick {
+		p.tick = base
+		return nil
+	}
+	for t := base + 1; t <= p.tick; t++ {
+		if err := p.step(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// step applies the pending commands stamped with tick t, then runs the
+// predicted systems once, flushing the ecs.World's command buffer after each
+// pass as the Server does.
+func (p *Predictor) step(t uint64) error {
+	if p.apply != nil {
+		for i := 0; i < p.count; i++ {
+			if cmd := p.pending[(p.first+i)%pendingCommands]; cmd.Tick == t {
+				p.apply(p.world, cmd)
+			}
+		}
+	}
+	if err := p.systems.UpdateSystems(p.world); err != nil {
+		return err
+	}
+	if p.ecsWorld == nil {
+		return p.systems.UpdateSystemsForEntities(p.world, p.entitySource())
+	}
+	if err := p.ecsWorld.FlushCommands(); err != nil {
+		return err
+	}
+	if err := p.systems.UpdateSystemsForWorld(p.world, p.ecsWorld); err != nil {
+		return err
+	}
+	return p.ecsWorld.FlushCommands()
+}
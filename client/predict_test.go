@@ -0,0 +1,90 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/mechanical-lich/mlge/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+// predictWorld is a one-player world: the server moves X by V each tick.
+type predictWorld struct{ X, V int }
+
+type predictMove struct{}
+
+func (predictMove) UpdateSimulation(world any) error {
+	w := world.(*predictWorld)
+	w.X += w.V
+	return nil
+}
+
+func (predictMove) UpdateEntitySimulation(any, *ecs.Entity) error { return nil }
+func (predictMove) Requires() []ecs.ComponentType                 { return nil }
+
+func newTestPredictor(w *predictWorld) *Predictor {
+	p := NewPredictor(20, func(world any, cmd *transport.Command) {
+		world.(*predictWorld).V = cmd.Payload.(int)
+	})
+	p.AddSystem(predictMove{})
+	p.bind(w, nil, func() []*ecs.Entity { return nil })
+	return p
+}
+
+func TestPredictorAppliesCommandsImmediately(t *testing.T) {
+	assert := assert.New(t)
+	w := &predictWorld{}
+	p := newTestPredictor(w)
+
+	cmd := &transport.Command{Type: "move", Payload: 2}
+	p.stamp(cmd)
+	assert.Equal(uint64(1), cmd.Tick)
+	assert.NoError(p.advance(60))
+	assert.Equal(0, w.X, "Expected no tick before a full tick interval")
+	assert.NoError(p.advance(60))
+	assert.NoError(p.advance(60))
+	assert.Equal(uint64(1), p.Tick())
+	assert.Equal(predictWorld{X: 2, V: 2}, *w)
+	assert.Equal(1, p.Pending())
+}
+
+func TestPredictorReconcilesWithServer(t *testing.T) {
+	assert := assert.New(t)
+	w := &predictWorld{}
+	p := newTestPredictor(w)
+
+	// Predict ticks 1-4, changing velocity on ticks 1 and 3.
+	p.stamp(&transport.Command{Type: "move", Payload: 1})
+	assert.NoError(p.advance(20))
+	assert.NoError(p.advance(20))
+	p.stamp(&transport.Command{Type: "move", Payload: 3})
+	assert.NoError(p.advance(20))
+	assert.NoError(p.advance(20))
+	assert.Equal(predictWorld{X: 8, V: 3}, *w)
+
+	// The server has simulated up to tick 2, but moved X further than
+	// predicted; decoding its snapshot rewinds the world to that state.
+	*w = predictWorld{X: 5, V: 1}
+	assert.NoError(p.reconcile(&transport.Snapshot{ClientTicks: map[transport.ClientID]uint64{1: 2}}, 1))
+	assert.Equal(predictWorld{X: 11, V: 3}, *w, "Expected pending commands replayed on server state")
+	assert.Equal(1, p.Pending())
+	assert.Equal(uint64(4), p.Tick())
+
+	assert.NoError(p.reconcile(&transport.Snapshot{}, 1), "Expected snapshots without a tick for the client to be ignored")
+	assert.Equal(1, p.Pending())
+
+	*w = predictWorld{X: 20, V: 3}
+	assert.NoError(p.reconcile(&transport.Snapshot{ClientTicks: map[transport.ClientID]uint64{1: 6}}, 1))
+	assert.Equal(predictWorld{X: 20, V: 3}, *w)
+	assert.Equal(0, p.Pending())
+	assert.Equal(uint64(6), p.Tick(), "Expected the prediction to catch up with the server")
+}
+
+func TestPredictorDropsOldestPendingCommand(t *testing.T) {
+	p := newTestPredictor(&predictWorld{})
+	for i := 0; i < pendingCommands+5; i++ {
+		p.stamp(&transport.Command{Type: "move", Payload: i})
+	}
+	assert.Equal(t, pendingCommands, p.Pending())
+	assert.Equal(t, 5, p.pending[p.first].Payload)
+}
@@ -1,6 +1,10 @@
 package client
 
-import "github.com/mechanical-lich/mlge/ecs"
+import (
+	"time"
+
+	"github.com/mechanical-lich/mlge/ecs"
+)
 
 // RenderSystem is the client-side counterpart to simulation.SimulationSystem.
 //
@@ -27,28 +31,84 @@ type RenderSystem interface {
 }
 
 // RenderSystemManager holds an ordered list of RenderSystems and
-// drives them each Ebitengine frame.
+// drives them each Ebitengine frame. Systems run ordered by their
+// ecs.SystemOptions, like ecs.SystemManager.
 type RenderSystemManager struct {
 	systems            []RenderSystem
 	cachedRequirements [][]ecs.ComponentType
+	schedule           ecs.Schedule
+	queryWorld         *ecs.World
+	queries            []*ecs.Query
+	profiler           *ecs.Profiler
 }
 
-// AddSystem appends a system to the execution order.
+// AddSystem registers a system using the options it declares via
+// ecs.OptionedSystem, if any.
 func (m *RenderSystemManager) AddSystem(s RenderSystem) {
+	m.AddSystemWithOptions(s, ecs.OptionsFor(s))
+}
+
+// AddSystemWithOptions registers a system with explicit scheduling options.
+// Ordering errors (cycles, duplicate names) are reported by Resolve and by
+// the Update methods.
+func (m *RenderSystemManager) AddSystemWithOptions(s RenderSystem, opts ecs.SystemOptions) {
 	if m.systems == nil {
 		m.systems = make([]RenderSystem, 0)
 		m.cachedRequirements = make([][]ecs.ComponentType, 0)
 	}
 	m.systems = append(m.systems, s)
 	m.cachedRequirements = append(m.cachedRequirements, s.Requires())
+	m.schedule.Add(opts)
+}
+
+// SetEnabled enables or disables the system registered under name without
+// removing it. Returns an error if no system has that name.
+func (m *RenderSystemManager) SetEnabled(name string, enabled bool) error {
+	return m.schedule.SetEnabled(name, enabled)
+}
+
+// Enabled reports whether the system registered under name is enabled.
+func (m *RenderSystemManager) Enabled(name string) bool {
+	return m.schedule.Enabled(name)
+}
+
+// Resolve resolves the run order now and returns any ordering error.
+// Useful to validate system registration at startup.
+func (m *RenderSystemManager) Resolve() error {
+	_, err := m.schedule.Order()
+	return err
+}
+
+// SetProfiler records per-system timings in p for every update pass (see
+// ecs.Profiler). Pass nil to stop profiling.
+func (m *RenderSystemManager) SetProfiler(p *ecs.Profiler) {
+	m.profiler = p
+}
+
+// Profiler returns the profiler set with SetProfiler, or nil.
+func (m *RenderSystemManager) Profiler() *ecs.Profiler {
+	return m.profiler
+}
+
+// record adds a timing sample for system i if profiling is enabled.
+func (m *RenderSystemManager) record(i int, pass ecs.Pass, start time.Time, entities int) {
+	if m.profiler != nil {
+		m.profiler.Record(i, ecs.SystemName(m.systems[i], m.schedule.Options(i)), pass, time.Since(start), entities)
+	}
 }
 
 // UpdateSystems calls UpdateRender on every registered system.
 func (m *RenderSystemManager) UpdateSystems(world any) error {
-	for _, s := range m.systems {
-		if err := s.UpdateRender(world); err != nil {
+	order, err := m.schedule.Runnable(ecs.GlobalPass, world)
+	if err != nil {
+		return err
+	}
+	for _, i := range order {
+		start := m.profiler.Start()
+		if err := m.systems[i].UpdateRender(world); err != nil {
 			return err
 		}
+		m.record(i, ecs.GlobalPass, start, 0)
 	}
 	return nil
 }
@@ -56,18 +116,57 @@ func (m *RenderSystemManager) UpdateSystems(world any) error {
 // UpdateSystemsForEntities iterates every system, then every entity, calling
 // UpdateEntityRender when the entity satisfies the system's Requires().
 func (m *RenderSystemManager) UpdateSystemsForEntities(world any, entities []*ecs.Entity) error {
-	for i, s := range m.systems {
+	order, err := m.schedule.Runnable(ecs.EntityPass, world)
+	if err != nil {
+		return err
+	}
+	for _, i := range order {
+		s := m.systems[i]
 		required := m.cachedRequirements[i]
+		start := m.profiler.Start()
+		processed := 0
 		for _, entity := range entities {
-			if ecs.InanimateComponentType != "" && entity.HasComponent(ecs.InanimateComponentType) {
+			if ecs.IsInanimate(entity) {
 				continue
 			}
 			if entity.HasComponentsSlice(required) {
 				if err := s.UpdateEntityRender(world, entity); err != nil {
 					return err
 				}
+				processed++
 			}
 		}
+		m.record(i, ecs.EntityPass, start, processed)
+	}
+	return nil
+}
+
+// UpdateSystemsForWorld is the query-driven form of UpdateSystemsForEntities.
+// Each system walks the cached ecs.World.Query for its Requires().
+func (m *RenderSystemManager) UpdateSystemsForWorld(world any, w *ecs.World) error {
+	order, err := m.schedule.Runnable(ecs.EntityPass, world)
+	if err != nil {
+		return err
+	}
+	if m.queryWorld != w || len(m.queries) != len(m.systems) {
+		m.queryWorld = w
+		m.queries = ecs.WorldQueries(w, m.cachedRequirements)
+	}
+	for _, i := range order {
+		s := m.systems[i]
+		start := m.profiler.Start()
+		processed := 0
+		err := m.queries[i].Each(func(entity *ecs.Entity) error {
+			if ecs.IsInanimate(entity) {
+				return nil
+			}
+			processed++
+			return s.UpdateEntityRender(world, entity)
+		})
+		if err != nil {
+			return err
+		}
+		m.record(i, ecs.EntityPass, start, processed)
 	}
 	return nil
 }
@@ -0,0 +1,20 @@
+// Command blueprintlint validates JSON blueprint files.
+//
+//	go run github.com/mechanical-lich/mlge/cmd/blueprintlint [-allow-unknown] data/blueprints
+//
+// It only knows the engine's built-in components, so pass -allow-unknown when
+// linting game blueprints, or build a game-specific copy that registers the
+// game's components (see package ecs/blueprintlint).
+package main
+
+import (
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/mechanical-lich/mlge/ecs/basecomponents"
+	"github.com/mechanical-lich/mlge/ecs/blueprintlint"
+)
+
+func main() {
+	f := ecs.NewJSONFactory()
+	basecomponents.RegisterComponents(f)
+	blueprintlint.Main(f)
+}
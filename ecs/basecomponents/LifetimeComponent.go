@@ -0,0 +1,17 @@
+package basecomponents
+
+import "github.com/mechanical-lich/mlge/ecs"
+
+const Lifetime ecs.ComponentType = "LifetimeComponent"
+
+// LifetimeComponent despawns its entity after a number of updates.
+// LifetimeSystem counts Ticks down by one per update and despawns the entity
+// on the update it reaches zero, so an entity spawned with Ticks: 3 is
+// processed on three updates.
+type LifetimeComponent struct {
+	Ticks int
+}
+
+func (lc LifetimeComponent) GetType() ecs.ComponentType {
+	return Lifetime
+}
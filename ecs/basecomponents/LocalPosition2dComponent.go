@@ -0,0 +1,21 @@
+package basecomponents
+
+import "github.com/mechanical-lich/mlge/ecs"
+
+const LocalPosition2d ecs.ComponentType = "LocalPosition2dComponent"
+
+// LocalPosition2dComponent is an entity's position relative to its parent
+// (see ecs.World.SetParent). TransformSystem writes parent position + local
+// position into the entity's Position2dComponent each update.
+type LocalPosition2dComponent struct {
+	X, Y float64
+}
+
+func (pc LocalPosition2dComponent) GetType() ecs.ComponentType {
+	return LocalPosition2d
+}
+
+func (pc *LocalPosition2dComponent) SetPosition(x float64, y float64) {
+	pc.X = x
+	pc.Y = y
+}
@@ -0,0 +1,22 @@
+package basecomponents
+
+import "github.com/mechanical-lich/mlge/ecs"
+
+const LocalPosition3d ecs.ComponentType = "LocalPosition3dComponent"
+
+// LocalPosition3dComponent is an entity's position relative to its parent
+// (see ecs.World.SetParent). TransformSystem writes parent position + local
+// position into the entity's Position3dComponent each update.
+type LocalPosition3dComponent struct {
+	X, Y, Z float64
+}
+
+func (pc LocalPosition3dComponent) GetType() ecs.ComponentType {
+	return LocalPosition3d
+}
+
+func (pc *LocalPosition3dComponent) SetPosition(x float64, y float64, z float64) {
+	pc.X = x
+	pc.Y = y
+	pc.Z = z
+}
@@ -0,0 +1,64 @@
+package basecomponents
+
+import (
+	"slices"
+
+	"github.com/mechanical-lich/mlge/ecs"
+)
+
+const Timers ecs.ComponentType = "TimerComponent"
+
+// Timer is one countdown in a TimerComponent. TimerSystem counts Ticks down
+// by one per update and fires the timer on the update it reaches zero.
+type Timer struct {
+	// Name identifies the timer to TimerSystem.OnTimer and Cancel.
+	Name string
+
+	// Ticks is the number of updates left until the timer fires.
+	Ticks int
+
+	// Interval, if positive, restarts the timer with that many ticks after it
+	// fires. Otherwise the timer is removed once it fires.
+	Interval int
+
+	// Fire is called when the timer fires. It cannot be loaded from a
+	// blueprint; data-driven timers use TimerSystem.OnTimer instead.
+	Fire func(world any, e *ecs.Entity) `json:"-"`
+}
+
+// TimerComponent holds an entity's pending timers.
+type TimerComponent struct {
+	Timers []Timer
+}
+
+func (tc TimerComponent) GetType() ecs.ComponentType {
+	return Timers
+}
+
+// Add starts a timer.
+func (tc *TimerComponent) Add(timer Timer) {
+	tc.Timers = append(tc.Timers, timer)
+}
+
+// Cancel removes the timers with the given name, reporting whether any existed.
+func (tc *TimerComponent) Cancel(name string) bool {
+	n := len(tc.Timers)
+	tc.Timers = slices.DeleteFunc(tc.Timers, func(t Timer) bool { return t.Name == name })
+	return len(tc.Timers) != n
+}
+
+// AddTimer starts a timer on e, adding a TimerComponent if it has none.
+//
+//	basecomponents.AddTimer(e, basecomponents.Timer{Ticks: 3, Fire: explode})
+func AddTimer(e *ecs.Entity, timer Timer) {
+	if tc, ok := e.GetComponent(Timers).(*TimerComponent); ok {
+		tc.Add(timer)
+		return
+	}
+	tc := &TimerComponent{}
+	if existing, ok := e.GetComponent(Timers).(TimerComponent); ok {
+		tc.Timers = existing.Timers
+	}
+	tc.Add(timer)
+	e.AddComponent(tc)
+}
@@ -0,0 +1,168 @@
+package basecomponents
+
+import "github.com/mechanical-lich/mlge/ecs"
+
+// LifetimeSystem counts down LifetimeComponents and despawns entities whose
+// lifetime runs out. Counting updates rather than wall time keeps it
+// deterministic on a fixed-tick server.
+//
+// Expired entities that belong to an ecs.World are despawned through the
+// world's CommandBuffer, so the despawn lands at the next sync point. Set
+// OnExpire to handle expiry yourself, e.g. for entities kept outside a World;
+// it replaces the despawn.
+//
+// Like particle.ParticleSystem it satisfies ecs.SystemInterface,
+// simulation.SimulationSystem and client.RenderSystem. It runs in the
+// PostUpdate phase so an entity still gets its final update, and declares its
+// access so it can share a parallel stage unless OnExpire is set.
+type LifetimeSystem struct {
+	OnExpire func(world any, e *ecs.Entity)
+}
+
+func (s *LifetimeSystem) SystemOptions() ecs.SystemOptions {
+	opts := ecs.SystemOptions{Name: "lifetime", Phase: ecs.PostUpdate}
+	if s.OnExpire == nil {
+		opts.Writes = []ecs.ComponentType{Lifetime}
+	}
+	return opts
+}
+
+func (s *LifetimeSystem) Requires() []ecs.ComponentType {
+	return []ecs.ComponentType{Lifetime}
+}
+
+func (s *LifetimeSystem) UpdateSystem(data any) error { return nil }
+
+func (s *LifetimeSystem) UpdateEntity(data any, entity *ecs.Entity) error {
+	s.tick(data, entity)
+	return nil
+}
+
+func (s *LifetimeSystem) UpdateSimulation(world any) error { return nil }
+
+func (s *LifetimeSystem) UpdateEntitySimulation(world any, entity *ecs.Entity) error {
+	s.tick(world, entity)
+	return nil
+}
+
+func (s *LifetimeSystem) UpdateRender(world any) error { return nil }
+
+func (s *LifetimeSystem) UpdateEntityRender(world any, entity *ecs.Entity) error {
+	s.tick(world, entity)
+	return nil
+}
+
+func (s *LifetimeSystem) tick(world any, e *ecs.Entity) {
+	var left int
+	switch lc := e.GetComponent(Lifetime).(type) {
+	case *LifetimeComponent:
+		lc.Ticks--
+		left = lc.Ticks
+	case LifetimeComponent:
+		left = lc.Ticks - 1
+		if left > 0 {
+			if w := e.World(); w != nil {
+				w.Commands().AddComponent(e, LifetimeComponent{Ticks: left})
+			} else {
+				e.AddComponent(LifetimeComponent{Ticks: left})
+			}
+		}
+	default:
+		return
+	}
+	if left > 0 {
+		return
+	}
+	if s.OnExpire != nil {
+		s.OnExpire(world, e)
+	} else if w := e.World(); w != nil {
+		w.Commands().Despawn(e.ID)
+	}
+}
+
+// TimerSystem counts down the timers in TimerComponents and fires them: it
+// calls the timer's Fire function, then OnTimer, then restarts the timer if
+// it has an Interval or removes it. Like LifetimeSystem it counts updates, so
+// timers fire on the same tick on every run.
+//
+// Fire and OnTimer run arbitrary game code, so TimerSystem declares no
+// component access and always runs alone. Structural changes they make to an
+// ecs.World should go through its CommandBuffer. Timers added while firing
+// start counting on the next update.
+//
+// It satisfies ecs.SystemInterface, simulation.SimulationSystem and
+// client.RenderSystem.
+type TimerSystem struct {
+	// OnTimer, if set, is called for every timer that fires, after its Fire
+	// function. Use it for named timers loaded from blueprints.
+	OnTimer func(world any, e *ecs.Entity, timer Timer)
+}
+
+func (s *TimerSystem) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{Name: "timers"}
+}
+
+func (s *TimerSystem) Requires() []ecs.ComponentType {
+	return []ecs.ComponentType{Timers}
+}
+
+func (s *TimerSystem) UpdateSystem(data any) error { return nil }
+
+func (s *TimerSystem) UpdateEntity(data any, entity *ecs.Entity) error {
+	s.tick(data, entity)
+	return nil
+}
+
+func (s *TimerSystem) UpdateSimulation(world any) error { return nil }
+
+func (s *TimerSystem) UpdateEntitySimulation(world any, entity *ecs.Entity) error {
+	s.tick(world, entity)
+	return nil
+}
+
+func (s *TimerSystem) UpdateRender(world any) error { return nil }
+
+func (s *TimerSystem) UpdateEntityRender(world any, entity *ecs.Entity) error {
+	s.tick(world, entity)
+	return nil
+}
+
+func (s *TimerSystem) tick(world any, e *ecs.Entity) {
+	tc, ok := e.GetComponent(Timers).(*TimerComponent)
+	if !ok {
+		if value, isValue := e.GetComponent(Timers).(TimerComponent); isValue {
+			// Value components cannot be counted down in place; switch the
+			// entity to a pointer, the form JSONFactory creates.
+			tc = &TimerComponent{Timers: value.Timers}
+			e.AddComponent(tc)
+		} else {
+			return
+		}
+	}
+
+	var fired []Timer
+	kept := tc.Timers[:0]
+	for _, timer := range tc.Timers {
+		timer.Ticks--
+		if timer.Ticks > 0 {
+			kept = append(kept, timer)
+			continue
+		}
+		fired = append(fired, timer)
+		if timer.Interval > 0 {
+			timer.Ticks = timer.Interval
+			kept = append(kept, timer)
+		}
+	}
+	clear(tc.Timers[len(kept):])
+	tc.Timers = kept
+
+	for _, timer := range fired {
+		if timer.Fire != nil {
+			timer.Fire(world, e)
+		}
+		if s.OnTimer != nil {
+			s.OnTimer(world, e, timer)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package basecomponents
+
+import (
+	"testing"
+
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/mechanical-lich/mlge/simulation"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ ecs.SystemInterface         = (*LifetimeSystem)(nil)
+	_ simulation.SimulationSystem = (*LifetimeSystem)(nil)
+	_ ecs.SystemInterface         = (*TimerSystem)(nil)
+	_ simulation.SimulationSystem = (*TimerSystem)(nil)
+)
+
+func TestLifetimeSystemDespawnsAfterTicks(t *testing.T) {
+	assert := assert.New(t)
+	w := ecs.NewWorld()
+	short := w.Spawn()
+	short.AddComponent(&LifetimeComponent{Ticks: 2})
+	long := w.Spawn()
+	long.AddComponent(LifetimeComponent{Ticks: 3})
+
+	sm := &ecs.SystemManager{}
+	sm.AddSystem(&LifetimeSystem{})
+
+	for tick := 1; tick <= 3; tick++ {
+		assert.NoError(sm.UpdateSystemsForEntities(w, w.Entities()))
+		assert.Equal(tick < 2, w.Alive(short.ID), "short lifetime after tick %d", tick)
+		assert.Equal(tick < 3, w.Alive(long.ID), "long lifetime after tick %d", tick)
+	}
+}
+
+func TestLifetimeSystemOnExpire(t *testing.T) {
+	e := &ecs.Entity{}
+	e.AddComponent(&LifetimeComponent{Ticks: 1})
+	var expired []*ecs.Entity
+	s := &LifetimeSystem{OnExpire: func(_ any, e *ecs.Entity) { expired = append(expired, e) }}
+
+	assert.Nil(t, s.SystemOptions().Writes, "Expected OnExpire to make the system run alone")
+	assert.NoError(t, s.UpdateEntitySimulation(nil, e))
+	assert.Equal(t, []*ecs.Entity{e}, expired)
+}
+
+func TestTimerSystemFiresOnTick(t *testing.T) {
+	assert := assert.New(t)
+	e := &ecs.Entity{}
+	var log []string
+	AddTimer(e, Timer{Name: "once", Ticks: 2, Fire: func(any, *ecs.Entity) { log = append(log, "fire") }})
+	AddTimer(e, Timer{Name: "every", Ticks: 1, Interval: 2})
+	AddTimer(e, Timer{Name: "never", Ticks: 10})
+
+	s := &TimerSystem{OnTimer: func(_ any, _ *ecs.Entity, timer Timer) { log = append(log, timer.Name) }}
+	for tick := 1; tick <= 5; tick++ {
+		log = append(log, "tick")
+		assert.NoError(s.UpdateEntity(nil, e))
+	}
+	assert.Equal([]string{"tick", "every", "tick", "fire", "once", "tick", "every", "tick", "tick", "every"}, log)
+
+	tc := e.GetComponent(Timers).(*TimerComponent)
+	assert.Len(tc.Timers, 2)
+	assert.True(tc.Cancel("never"))
+	assert.False(tc.Cancel("never"))
+	assert.Len(tc.Timers, 1)
+}
+
+func TestTimerAddedWhileFiringWaitsForNextUpdate(t *testing.T) {
+	e := &ecs.Entity{}
+	fired := 0
+	var chain func(any, *ecs.Entity)
+	chain = func(_ any, e *ecs.Entity) {
+		fired++
+		AddTimer(e, Timer{Ticks: 1, Fire: chain})
+	}
+	AddTimer(e, Timer{Ticks: 1, Fire: chain})
+
+	s := &TimerSystem{}
+	s.UpdateEntity(nil, e)
+	s.UpdateEntity(nil, e)
+	assert.Equal(t, 2, fired)
+}
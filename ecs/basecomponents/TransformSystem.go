@@ -0,0 +1,123 @@
+package basecomponents
+
+import "github.com/mechanical-lich/mlge/ecs"
+
+// TransformSystem propagates positions down ecs.World parent/child links: a
+// child with a LocalPosition2dComponent (or 3d) gets its Position2dComponent
+// (or 3d) set to its parent's position plus the local offset. Parents are
+// updated before their children, so chains such as turret -> ship -> fleet
+// resolve in one update.
+//
+// Positions stored as pointers are updated in place and reported with
+// ecs.Entity.MarkChanged; value positions are replaced with AddComponent. A
+// missing position is added as a pointer, the form JSONFactory creates.
+//
+// It satisfies ecs.SystemInterface, simulation.SimulationSystem and
+// client.RenderSystem; all the work happens in the global pass, which needs
+// the world data to resolve to an *ecs.World (see ecs.WorldFrom). It runs in
+// the PostUpdate phase so movement systems have already moved the parents.
+type TransformSystem struct{}
+
+func (s *TransformSystem) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{Name: "transform", Phase: ecs.PostUpdate}
+}
+
+// Requires keeps the per-entity pass, which does nothing, to a narrow query.
+func (s *TransformSystem) Requires() []ecs.ComponentType {
+	return []ecs.ComponentType{LocalPosition2d}
+}
+
+func (s *TransformSystem) UpdateSystem(data any) error {
+	if w, ok := ecs.WorldFrom(data); ok {
+		s.Apply(w)
+	}
+	return nil
+}
+
+func (s *TransformSystem) UpdateEntity(data any, entity *ecs.Entity) error { return nil }
+
+func (s *TransformSystem) UpdateSimulation(world any) error { return s.UpdateSystem(world) }
+
+func (s *TransformSystem) UpdateEntitySimulation(world any, entity *ecs.Entity) error { return nil }
+
+func (s *TransformSystem) UpdateRender(world any) error { return s.UpdateSystem(world) }
+
+func (s *TransformSystem) UpdateEntityRender(world any, entity *ecs.Entity) error { return nil }
+
+// Apply updates every child position in w.
+func (s *TransformSystem) Apply(w *ecs.World) {
+	for _, e := range w.Entities() {
+		if w.Parent(e.ID) == ecs.NoEntity && len(w.Children(e.ID)) > 0 {
+			s.propagate(w, e)
+		}
+	}
+}
+
+func (s *TransformSystem) propagate(w *ecs.World, parent *ecs.Entity) {
+	for _, id := range w.Children(parent.ID) {
+		child := w.Get(id)
+		if local, ok := child.GetComponent(LocalPosition2d).(*LocalPosition2dComponent); ok {
+			propagate2d(parent, child, *local)
+		} else if local, ok := child.GetComponent(LocalPosition2d).(LocalPosition2dComponent); ok {
+			propagate2d(parent, child, local)
+		}
+		if local, ok := child.GetComponent(LocalPosition3d).(*LocalPosition3dComponent); ok {
+			propagate3d(parent, child, *local)
+		} else if local, ok := child.GetComponent(LocalPosition3d).(LocalPosition3dComponent); ok {
+			propagate3d(parent, child, local)
+		}
+		s.propagate(w, child)
+	}
+}
+
+func propagate2d(parent, child *ecs.Entity, local LocalPosition2dComponent) {
+	var origin Position2dComponent
+	switch p := parent.GetComponent(Position2d).(type) {
+	case *Position2dComponent:
+		origin = *p
+	case Position2dComponent:
+		origin = p
+	default:
+		return
+	}
+	x, y := origin.X+local.X, origin.Y+local.Y
+	switch c := child.GetComponent(Position2d).(type) {
+	case *Position2dComponent:
+		if c.X != x || c.Y != y {
+			c.SetPosition(x, y)
+			child.MarkChanged(Position2d)
+		}
+	case Position2dComponent:
+		if c.X != x || c.Y != y {
+			child.AddComponent(Position2dComponent{X: x, Y: y})
+		}
+	default:
+		child.AddComponent(&Position2dComponent{X: x, Y: y})
+	}
+}
+
+func propagate3d(parent, child *ecs.Entity, local LocalPosition3dComponent) {
+	var origin Position3dComponent
+	switch p := parent.GetComponent(Position3d).(type) {
+	case *Position3dComponent:
+		origin = *p
+	case Position3dComponent:
+		origin = p
+	default:
+		return
+	}
+	x, y, z := origin.X+local.X, origin.Y+local.Y, origin.Z+local.Z
+	switch c := child.GetComponent(Position3d).(type) {
+	case *Position3dComponent:
+		if c.X != x || c.Y != y || c.Z != z {
+			c.SetPosition(x, y, z)
+			child.MarkChanged(Position3d)
+		}
+	case Position3dComponent:
+		if c.X != x || c.Y != y || c.Z != z {
+			child.AddComponent(Position3dComponent{X: x, Y: y, Z: z})
+		}
+	default:
+		child.AddComponent(&Position3dComponent{X: x, Y: y, Z: z})
+	}
+}
@@ -0,0 +1,14 @@
+package basecomponents
+
+import "github.com/mechanical-lich/mlge/ecs"
+
+// RegisterComponents registers the built-in components with a JSONFactory
+// under their ComponentType names.
+func RegisterComponents(f *ecs.JSONFactory) {
+	f.RegisterComponent(string(Position2d), func() ecs.Component { return &Position2dComponent{} })
+	f.RegisterComponent(string(Position3d), func() ecs.Component { return &Position3dComponent{} })
+	f.RegisterComponent(string(LocalPosition2d), func() ecs.Component { return &LocalPosition2dComponent{} })
+	f.RegisterComponent(string(LocalPosition3d), func() ecs.Component { return &LocalPosition3dComponent{} })
+	f.RegisterComponent(string(Lifetime), func() ecs.Component { return &LifetimeComponent{} })
+	f.RegisterComponent(string(Timers), func() ecs.Component { return &TimerComponent{} })
+}
@@ -0,0 +1,97 @@
+// Package blueprintlint checks JSON blueprint files against a JSONFactory's
+// registered components. Games wrap it in a tiny command that registers their
+// own components:
+//
+//	func main() {
+//	    f := ecs.NewJSONFactory()
+//	    game.RegisterComponents(f)
+//	    blueprintlint.Main(f)
+//	}
+//
+// cmd/blueprintlint is such a command for the engine's built-in components.
+package blueprintlint
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mechanical-lich/mlge/ecs"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK       = 0 // every blueprint is valid
+	ExitProblems = 1 // validation problems were reported
+	ExitFailure  = 2 // bad usage, or a file could not be read or parsed
+)
+
+// Options controls what Run reports.
+type Options struct {
+	// AllowUnknownComponents skips components with no registered constructor,
+	// for linting with a factory that only knows some of the game's components.
+	AllowUnknownComponents bool
+}
+
+// Run loads every path (a blueprint directory or a single .json file) into f,
+// validates the result and writes one line per problem to out. It returns one
+// of the Exit codes.
+func Run(f *ecs.JSONFactory, paths []string, opts Options, out io.Writer) int {
+	if len(paths) == 0 {
+		fmt.Fprintln(out, "blueprintlint: no blueprint paths given")
+		return ExitFailure
+	}
+	for _, path := range paths {
+		if err := load(f, path); err != nil {
+			fmt.Fprintln(out, err)
+			return ExitFailure
+		}
+	}
+
+	var problems ecs.ValidationErrors
+	if err := f.Validate(); err != nil && !errors.As(err, &problems) {
+		fmt.Fprintln(out, err)
+		return ExitFailure
+	}
+	reported := 0
+	for _, problem := range problems {
+		if opts.AllowUnknownComponents && errors.Is(problem, ecs.ErrUnknownComponent) {
+			continue
+		}
+		fmt.Fprintln(out, problem)
+		reported++
+	}
+	if reported > 0 {
+		fmt.Fprintf(out, "%d problem(s) in %d blueprint(s)\n", reported, len(f.GetBlueprintNames()))
+		return ExitProblems
+	}
+	return ExitOK
+}
+
+// Main parses the command line (flags, then blueprint paths), runs the lint
+// and exits the process with Run's exit code.
+func Main(f *ecs.JSONFactory) {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ExitOnError)
+	var opts Options
+	fs.BoolVar(&opts.AllowUnknownComponents, "allow-unknown", false, "do not report components without a registered constructor")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s [-allow-unknown] <blueprint dir or file>...\n", fs.Name())
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	os.Exit(Run(f, fs.Args(), opts, os.Stdout))
+}
+
+func load(f *ecs.JSONFactory, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return f.LoadBlueprintsFromDir(path)
+	}
+	return f.LoadBlueprintsFromFile(path)
+}
@@ -0,0 +1,48 @@
+package blueprintlint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/mechanical-lich/mlge/ecs/basecomponents"
+	"github.com/stretchr/testify/assert"
+)
+
+func lint(t *testing.T, content string, opts Options) (int, string) {
+	t.Helper()
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "units.json"), []byte(content), 0o644))
+	f := ecs.NewJSONFactory()
+	basecomponents.RegisterComponents(f)
+	var out strings.Builder
+	code := Run(f, []string{dir}, opts, &out)
+	return code, out.String()
+}
+
+func TestRunReportsProblems(t *testing.T) {
+	assert := assert.New(t)
+
+	code, out := lint(t, `{"crate": {"Position2dComponent": {"X": 1, "Z": 2}, "Lootable": {}}}`, Options{})
+
+	assert.Equal(ExitProblems, code)
+	assert.Contains(out, "blueprint crate: component Lootable: component not registered")
+	assert.Contains(out, "blueprint crate: component Position2dComponent: field Z: unknown field")
+	assert.Contains(out, "2 problem(s) in 1 blueprint(s)")
+}
+
+func TestRunAllowUnknownComponents(t *testing.T) {
+	code, out := lint(t, `{"crate": {"Position2dComponent": {"X": 1}, "Lootable": {}}}`, Options{AllowUnknownComponents: true})
+
+	assert.Equal(t, ExitOK, code)
+	assert.Empty(t, out)
+}
+
+func TestRunFailsOnUnparsableFile(t *testing.T) {
+	code, out := lint(t, `{"crate": [`, Options{})
+
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, out, "failed to parse")
+}
@@ -0,0 +1,227 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type commandKind uint8
+
+const (
+	commandSpawn commandKind = iota
+	commandDespawn
+	commandAddComponent
+	commandRemoveComponent
+	commandChanged // notifies observers of a component changed while deferring
+	commandSetTag
+	commandClearTag
+)
+
+type bufferedCommand struct {
+	kind          commandKind
+	entity        *Entity
+	id            EntityID
+	component     Component
+	componentType ComponentType
+	tag           Tag
+}
+
+// CommandBuffer records structural changes (spawn, despawn, add/remove
+// component) so they can be applied later at a well-defined sync point
+// instead of while a system pass is iterating over entities.
+//
+// Systems normally record into the world's buffer, obtained with
+// [World.Commands]. SystemManager applies it after each of its passes and
+// simulation.Server applies it after each system pass of a tick, so systems
+// never observe half-applied structural changes.
+//
+// Recording is safe for concurrent use. Commands are applied in the order
+// they were recorded; commands recorded by systems running concurrently on
+// different goroutines are applied in the order they reached the buffer.
+//
+// The zero value is an empty buffer.
+type CommandBuffer struct {
+	mu   sync.Mutex
+	cmds []bufferedCommand
+}
+
+// Spawn records adding e (typically built by a factory) to the world.
+// Components can be added to e directly before the buffer is applied.
+func (b *CommandBuffer) Spawn(e *Entity) {
+	b.push(bufferedCommand{kind: commandSpawn, entity: e})
+}
+
+// Despawn records removing the entity with the given ID from the world.
+// Despawning an ID that is already dead when the buffer is applied is a no-op.
+func (b *CommandBuffer) Despawn(id EntityID) {
+	b.push(bufferedCommand{kind: commandDespawn, id: id})
+}
+
+// AddComponent records adding (or replacing) a component on e. It is
+// skipped if e has been despawned by the time the buffer is applied.
+func (b *CommandBuffer) AddComponent(e *Entity, c Component) {
+	b.push(bufferedCommand{kind: commandAddComponent, entity: e, id: e.ID, component: c})
+}
+
+// RemoveComponent records removing a component from e. It is skipped if e
+// has been despawned by the time the buffer is applied.
+func (b *CommandBuffer) RemoveComponent(e *Entity, t ComponentType) {
+	b.push(bufferedCommand{kind: commandRemoveComponent, entity: e, id: e.ID, componentType: t})
+}
+
+// SetTag records adding t to e's tags. It is skipped if e has been despawned
+// by the time the buffer is applied.
+func (b *CommandBuffer) SetTag(e *Entity, t Tag) {
+	b.push(bufferedCommand{kind: commandSetTag, entity: e, id: e.ID, tag: t})
+}
+
+// ClearTag records removing t from e's tags. It is skipped if e has been
+// despawned by the time the buffer is applied.
+func (b *CommandBuffer) ClearTag(e *Entity, t Tag) {
+	b.push(bufferedCommand{kind: commandClearTag, entity: e, id: e.ID, tag: t})
+}
+
+// changed records an observer notification for the t component of e, which
+// was replaced or marked changed while its world deferred component changes.
+// Observers receive the component e has when the notification is applied.
+func (b *CommandBuffer) changed(e *Entity, t ComponentType) {
+	b.push(bufferedCommand{kind: commandChanged, entity: e, id: e.ID, componentType: t})
+}
+
+// Len returns the number of pending commands.
+func (b *CommandBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.cmds)
+}
+
+// Apply executes and clears the pending commands against w. Commands
+// recorded while Apply runs (e.g. by observers) stay pending until the next
+// Apply. Every command is attempted; failures are joined into the returned error.
+func (b *CommandBuffer) Apply(w *World) error {
+	b.mu.Lock()
+	cmds := b.cmds
+	b.cmds = nil
+	b.mu.Unlock()
+
+	var errs []error
+	for _, cmd := range cmds {
+		switch cmd.kind {
+		case commandSpawn:
+			if w == nil {
+				errs = append(errs, errors.New("ecs: spawn command requires a world"))
+				continue
+			}
+			if _, err := w.Add(cmd.entity); err != nil {
+				errs = append(errs, fmt.Errorf("ecs: spawn %s: %w", cmd.entity.Blueprint, err))
+			}
+		case commandDespawn:
+			if w == nil {
+				errs = append(errs, errors.New("ecs: despawn command requires a world"))
+				continue
+			}
+			w.Despawn(cmd.id)
+		case commandAddComponent:
+			if cmd.live() {
+				cmd.entity.AddComponent(cmd.component)
+			}
+		case commandRemoveComponent:
+			if cmd.live() {
+				cmd.entity.RemoveComponent(cmd.componentType)
+			}
+		case commandChanged:
+			if c, ok := cmd.entity.Components[cmd.componentType]; ok && cmd.live() {
+				w.notifyChanged(cmd.entity, cmd.componentType, c)
+			}
+		case commandSetTag:
+			if cmd.live() {
+				cmd.entity.SetTag(cmd.tag)
+			}
+		case commandClearTag:
+			if cmd.live() {
+				cmd.entity.ClearTag(cmd.tag)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// live reports whether the entity of an entity command is still the
+// one it was recorded against. A despawned entity has left its world, and a
+// pooled one may since have been respawned under a new ID; an entity that
+// was not yet in a world when recorded must have been spawned since.
+func (cmd *bufferedCommand) live() bool {
+	w := cmd.entity.world
+	if w == nil {
+		return false
+	}
+	return cmd.id == NoEntity || (cmd.id == cmd.entity.ID && w.Alive(cmd.id))
+}
+
+func (b *CommandBuffer) push(cmd bufferedCommand) {
+	b.mu.Lock()
+	b.cmds = append(b.cmds, cmd)
+	b.mu.Unlock()
+}
+
+// Commands returns the world's command buffer. Record structural changes
+// here while iterating entities or queries.
+func (w *World) Commands() *CommandBuffer {
+	return &w.commands
+}
+
+// FlushCommands applies the world's pending commands.
+func (w *World) FlushCommands() error {
+	return w.commands.Apply(w)
+}
+
+// deferMode is how much of a component change a World defers.
+type deferMode uint8
+
+const (
+	deferNone       deferMode = iota
+	deferStructural           // new component types, removals and notifications
+	deferAll                  // also replacements of existing components
+)
+
+// DeferComponentChanges defers the structural part of component changes on
+// the world's entities until the returned function is called: AddComponent of
+// a type the entity lacks, RemoveComponent, SetTag and ClearTag are recorded
+// instead of made, while AddComponent replacing an existing component updates
+// the entity at once. Observer notifications, including those from MarkChanged, are held
+// back too. The returned function stops deferring, applies the recorded
+// changes in order, updating queries, and notifies observers of all of them.
+//
+// While deferring, component and tag changes never touch the world's indexes
+// or observers, so systems running on several goroutines may make them as long
+// as each entity is only changed by one goroutine at a time;
+// simulation.SimulationSystemManager defers around each batched per-entity
+// stage. Spawn and despawn through Commands instead, and call the returned
+// function on the goroutine that deferred, once every other goroutine is
+// done. Calls while already deferring return a no-op.
+func (w *World) DeferComponentChanges() (apply func() error) {
+	return w.deferChanges(deferStructural)
+}
+
+// DeferAllComponentChanges is DeferComponentChanges that also records
+// AddComponent calls replacing an existing component, so no entity's
+// component map is written until the returned function is called. Use it when
+// several goroutines may change the same entity; values written back are only
+// visible once the changes are applied.
+// simulation.SimulationSystemManager defers this way around each global
+// stage it runs in parallel.
+func (w *World) DeferAllComponentChanges() (apply func() error) {
+	return w.deferChanges(deferAll)
+}
+
+func (w *World) deferChanges(mode deferMode) func() error {
+	if w.deferring != deferNone {
+		return func() error { return nil }
+	}
+	w.deferring = mode
+	return func() error {
+		w.deferring = deferNone
+		return w.deferred.Apply(w)
+	}
+}
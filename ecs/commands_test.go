@@ -0,0 +1,95 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// splitSystem despawns every entity it visits and spawns two replacements,
+// recording both through the world's command buffer.
+type splitSystem struct{ visited int }
+
+func (s *splitSystem) UpdateSystem(data any) error { return nil }
+
+func (s *splitSystem) UpdateEntity(data any, entity *Entity) error {
+	s.visited++
+	w := entity.World()
+	w.Commands().Despawn(entity.ID)
+	for i := 0; i < 2; i++ {
+		child := &Entity{Blueprint: "child"}
+		w.Commands().Spawn(child)
+		w.Commands().AddComponent(child, TestComponent2{})
+	}
+	return nil
+}
+
+func (s *splitSystem) Requires() []ComponentType { return []ComponentType{testComponentType} }
+
+func TestCommandBufferDefersStructuralChanges(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	for i := 0; i < 3; i++ {
+		w.Spawn().AddComponent(TestComponent{})
+	}
+	sys := &splitSystem{}
+	sm := &SystemManager{}
+	sm.AddSystem(sys)
+
+	err := sm.UpdateSystemsForEntities(w, w.Entities())
+
+	assert.Nil(err)
+	assert.Equal(3, sys.visited, "Expected the pass to see only the entities present when it started")
+	assert.Equal(0, w.Commands().Len(), "Expected the manager to flush the buffer after the pass")
+	assert.Equal(6, w.Len())
+	assert.Equal(6, w.Query(testComponent2Type).Len())
+	assert.Equal(0, w.Query(testComponentType).Len())
+}
+
+func TestCommandBufferFlushesAfterWorldPass(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	w.Spawn().AddComponent(TestComponent{})
+	sys := &splitSystem{}
+	sm := &SystemManager{}
+	sm.AddSystem(sys)
+
+	assert.Nil(sm.UpdateSystemsForWorld(nil, w))
+	assert.Equal(1, sys.visited)
+	assert.Equal(2, w.Len())
+}
+
+func TestCommandBufferAppliesInOrder(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	e := w.Spawn()
+	var b CommandBuffer
+
+	b.AddComponent(e, TestComponent{})
+	b.RemoveComponent(e, testComponentType)
+	b.AddComponent(e, TestComponent2{})
+	assert.Equal(3, b.Len())
+	assert.False(e.HasComponent(testComponent2Type), "Expected nothing to change before Apply")
+
+	assert.Nil(b.Apply(w))
+	assert.False(e.HasComponent(testComponentType))
+	assert.True(e.HasComponent(testComponent2Type))
+	assert.Equal(0, b.Len())
+}
+
+func TestCommandBufferReportsErrors(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	owned := w.Spawn()
+	stale := w.Spawn().ID
+	w.Despawn(stale)
+	var b CommandBuffer
+
+	b.Spawn(owned)
+	b.Despawn(stale)
+	b.Spawn(&Entity{})
+
+	err := b.Apply(w)
+	assert.ErrorIs(err, ErrEntityOwned)
+	assert.Equal(2, w.Len(), "Expected the remaining commands to still apply")
+}
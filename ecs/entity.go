@@ -4,15 +4,47 @@ package ecs
 type Entity struct {
 	Components map[ComponentType]Component
 	Blueprint  string
+
+	// ID is assigned by the owning World. It is NoEntity for entities that
+	// have not been added to a World.
+	ID EntityID
+
+	world *World
+	tags  TagSet
+	pool  *EntityPool // the pool that created or holds the entity, if any
+}
+
+// World - Returns the World that owns the entity, or nil if it has not been added to one.
+func (entity *Entity) World() *World {
+	return entity.world
 }
 
 // AddComponent - Adds the provided component to the entity.
 func (entity *Entity) AddComponent(c Component) {
+	t := c.GetType()
+	_, existed := entity.Components[t]
+	if entity.world != nil && (entity.world.deferring == deferAll || entity.world.deferring != deferNone && !existed) {
+		entity.world.deferred.AddComponent(entity, c)
+		return
+	}
 	if entity.Components == nil {
 		entity.Components = make(map[ComponentType]Component)
 	}
 
-	entity.Components[c.GetType()] = c
+	entity.Components[t] = c
+	if entity.world == nil {
+		return
+	}
+	if existed {
+		if entity.world.deferring != deferNone {
+			entity.world.deferred.changed(entity, t)
+			return
+		}
+		entity.world.notifyChanged(entity, t, c)
+		return
+	}
+	entity.world.componentAdded(entity, t)
+	entity.world.notifyAdded(entity, t, c)
 }
 
 // HasComponent - Returns if the entity has the
@@ -48,9 +80,18 @@ func (entity *Entity) GetComponent(name ComponentType) Component {
 
 // RemoveComponent - Removes the component from the entity.
 func (entity *Entity) RemoveComponent(name ComponentType) {
+	if entity.world != nil && entity.world.deferring != deferNone {
+		entity.world.deferred.RemoveComponent(entity, name)
+		return
+	}
 	if entity.Components == nil {
 		entity.Components = make(map[ComponentType]Component)
 	}
 
+	old, existed := entity.Components[name]
 	delete(entity.Components, name)
+	if entity.world != nil && existed {
+		entity.world.componentRemoved(entity, name)
+		entity.world.notifyRemoved(entity, name, old)
+	}
 }
@@ -0,0 +1,160 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrDeadEntity is returned when a hierarchy or relation call names an
+// entity that is not alive in the world.
+var ErrDeadEntity = errors.New("entity is not alive")
+
+// Relation names a directed link between two entities, such as "owned_by" or
+// "targets". An entity may have any number of links per relation.
+type Relation string
+
+type relationIndex struct {
+	forward map[EntityID][]EntityID // from -> targets, in insertion order
+	reverse map[EntityID][]EntityID // target -> sources, in insertion order
+}
+
+// SetParent makes parent the parent of child, replacing any previous parent.
+// Passing NoEntity as parent detaches child. Despawning a parent despawns its
+// children. Returns an error if either entity is dead or if the link would
+// create a cycle.
+func (w *World) SetParent(child, parent EntityID) error {
+	if !w.Alive(child) {
+		return fmt.Errorf("ecs: set parent of %s: %w", child, ErrDeadEntity)
+	}
+	if parent != NoEntity {
+		if !w.Alive(parent) {
+			return fmt.Errorf("ecs: set parent of %s to %s: %w", child, parent, ErrDeadEntity)
+		}
+		for p := parent; p != NoEntity; p = w.parents[p] {
+			if p == child {
+				return fmt.Errorf("ecs: set parent of %s to %s would create a cycle", child, parent)
+			}
+		}
+	}
+
+	if old, ok := w.parents[child]; ok {
+		w.children[old] = slices.DeleteFunc(w.children[old], func(id EntityID) bool { return id == child })
+		if len(w.children[old]) == 0 {
+			delete(w.children, old)
+		}
+		delete(w.parents, child)
+	}
+	if parent == NoEntity {
+		return nil
+	}
+	if w.parents == nil {
+		w.parents = make(map[EntityID]EntityID)
+		w.children = make(map[EntityID][]EntityID)
+	}
+	w.parents[child] = parent
+	w.children[parent] = append(w.children[parent], child)
+	return nil
+}
+
+// Parent returns the entity's parent, or NoEntity if it has none.
+func (w *World) Parent(id EntityID) EntityID {
+	return w.parents[id]
+}
+
+// Children returns the entity's children in the order they were attached.
+// The slice is owned by the World and must not be modified.
+func (w *World) Children(id EntityID) []EntityID {
+	return w.children[id]
+}
+
+// Relate adds a link from -> to under relation. Adding an existing link is a no-op.
+func (w *World) Relate(from EntityID, relation Relation, to EntityID) error {
+	if !w.Alive(from) || !w.Alive(to) {
+		return fmt.Errorf("ecs: relate %s %s %s: %w", from, relation, to, ErrDeadEntity)
+	}
+	if w.relations == nil {
+		w.relations = make(map[Relation]*relationIndex)
+	}
+	idx := w.relations[relation]
+	if idx == nil {
+		idx = &relationIndex{forward: make(map[EntityID][]EntityID), reverse: make(map[EntityID][]EntityID)}
+		w.relations[relation] = idx
+	}
+	if slices.Contains(idx.forward[from], to) {
+		return nil
+	}
+	idx.forward[from] = append(idx.forward[from], to)
+	idx.reverse[to] = append(idx.reverse[to], from)
+	return nil
+}
+
+// Unrelate removes the link from -> to under relation, reporting whether it existed.
+func (w *World) Unrelate(from EntityID, relation Relation, to EntityID) bool {
+	idx := w.relations[relation]
+	if idx == nil || !slices.Contains(idx.forward[from], to) {
+		return false
+	}
+	idx.unlink(from, to)
+	return true
+}
+
+// Related returns the entities from links to under relation, e.g. the targets
+// of an archer. The slice is owned by the World and must not be modified.
+func (w *World) Related(from EntityID, relation Relation) []EntityID {
+	if idx := w.relations[relation]; idx != nil {
+		return idx.forward[from]
+	}
+	return nil
+}
+
+// RelatedTo returns the entities that link to under relation, e.g. everything
+// "owned_by" a player. The slice is owned by the World and must not be modified.
+func (w *World) RelatedTo(to EntityID, relation Relation) []EntityID {
+	if idx := w.relations[relation]; idx != nil {
+		return idx.reverse[to]
+	}
+	return nil
+}
+
+// Relations returns the names of every relation the entity links from.
+func (w *World) Relations(from EntityID) []Relation {
+	var names []Relation
+	for name, idx := range w.relations {
+		if len(idx.forward[from]) > 0 {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// unlinkEntity removes every hierarchy and relation link involving id and
+// despawns its children the way id is despawned. Called by Despawn and Forget
+// before the entity is removed.
+func (w *World) unlinkEntity(id EntityID, keepGeneration bool) {
+	for _, child := range slices.Clone(w.children[id]) {
+		w.despawn(child, keepGeneration)
+	}
+	w.SetParent(id, NoEntity)
+
+	for _, idx := range w.relations {
+		for _, to := range slices.Clone(idx.forward[id]) {
+			idx.unlink(id, to)
+		}
+		for _, from := range slices.Clone(idx.reverse[id]) {
+			idx.unlink(from, id)
+		}
+	}
+}
+
+func (idx *relationIndex) unlink(from, to EntityID) {
+	idx.forward[from] = slices.DeleteFunc(idx.forward[from], func(id EntityID) bool { return id == to })
+	if len(idx.forward[from]) == 0 {
+		delete(idx.forward, from)
+	}
+	idx.reverse[to] = slices.DeleteFunc(idx.reverse[to], func(id EntityID) bool { return id == from })
+	if len(idx.reverse[to]) == 0 {
+		delete(idx.reverse, to)
+	}
+}
@@ -0,0 +1,114 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorldSetParent(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	ship := w.Spawn().ID
+	turret := w.Spawn().ID
+	assert.NoError(w.SetParent(turret, ship))
+	assert.Equal(ship, w.Parent(turret))
+	assert.Equal([]EntityID{turret}, w.Children(ship))
+
+	other := w.Spawn().ID
+	assert.NoError(w.SetParent(turret, other), "Expected reparenting to succeed")
+	assert.Empty(w.Children(ship), "Expected old parent to lose the child")
+	assert.Equal([]EntityID{turret}, w.Children(other))
+
+	assert.NoError(w.SetParent(turret, NoEntity))
+	assert.Equal(NoEntity, w.Parent(turret))
+	assert.Empty(w.Children(other))
+}
+
+func TestWorldSetParentRejectsCyclesAndDeadEntities(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	a := w.Spawn().ID
+	b := w.Spawn().ID
+	c := w.Spawn().ID
+	assert.NoError(w.SetParent(b, a))
+	assert.NoError(w.SetParent(c, b))
+	assert.Error(w.SetParent(a, c), "Expected cycle to be rejected")
+	assert.Error(w.SetParent(a, a), "Expected self-parenting to be rejected")
+
+	w.Despawn(w.Spawn().ID)
+	dead := w.Spawn().ID
+	w.Despawn(dead)
+	assert.ErrorIs(w.SetParent(a, dead), ErrDeadEntity)
+	assert.ErrorIs(w.SetParent(dead, a), ErrDeadEntity)
+}
+
+func TestWorldDespawnCascadesToChildren(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	fleet := w.Spawn().ID
+	ship := w.Spawn().ID
+	turret := w.Spawn().ID
+	bystander := w.Spawn().ID
+	w.SetParent(ship, fleet)
+	w.SetParent(turret, ship)
+
+	assert.True(w.Despawn(fleet))
+	assert.False(w.Alive(ship), "Expected child to be despawned")
+	assert.False(w.Alive(turret), "Expected grandchild to be despawned")
+	assert.True(w.Alive(bystander))
+	assert.Equal(1, w.Len())
+}
+
+func TestWorldDespawnChildDetachesFromParent(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	mount := w.Spawn().ID
+	rider := w.Spawn().ID
+	w.SetParent(rider, mount)
+
+	w.Despawn(rider)
+	assert.True(w.Alive(mount))
+	assert.Empty(w.Children(mount))
+}
+
+func TestWorldRelations(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	player := w.Spawn().ID
+	sword := w.Spawn().ID
+	shield := w.Spawn().ID
+	assert.NoError(w.Relate(sword, "owned_by", player))
+	assert.NoError(w.Relate(shield, "owned_by", player))
+	assert.NoError(w.Relate(sword, "owned_by", player), "Expected duplicate link to be a no-op")
+	assert.NoError(w.Relate(player, "targets", shield))
+
+	assert.Equal([]EntityID{player}, w.Related(sword, "owned_by"))
+	assert.Equal([]EntityID{sword, shield}, w.RelatedTo(player, "owned_by"))
+	assert.Equal([]Relation{"targets"}, w.Relations(player))
+
+	assert.True(w.Unrelate(sword, "owned_by", player))
+	assert.False(w.Unrelate(sword, "owned_by", player))
+	assert.Equal([]EntityID{shield}, w.RelatedTo(player, "owned_by"))
+}
+
+func TestWorldDespawnDropsRelations(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	archer := w.Spawn().ID
+	goblin := w.Spawn().ID
+	w.Relate(archer, "targets", goblin)
+
+	w.Despawn(goblin)
+	assert.Empty(w.Related(archer, "targets"))
+	assert.Empty(w.Relations(archer))
+
+	dead := goblin
+	assert.ErrorIs(w.Relate(archer, "targets", dead), ErrDeadEntity)
+}
@@ -0,0 +1,204 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Reserved blueprint fields: extendsKey lists parent blueprints and
+// removeKey lists inherited components the blueprint drops.
+const (
+	extendsKey = "extends"
+	removeKey  = "remove"
+)
+
+// BlueprintData maps component names to the parameters used to populate them.
+type BlueprintData map[string]map[string]interface{}
+
+// jsonBlueprint is a blueprint as written in its file, before inheritance is applied.
+type jsonBlueprint struct {
+	source     string
+	extends    []string
+	components BlueprintData // a nil parameter map removes an inherited component (see removeKey)
+	imported   bool          // imported from the legacy text format; kept by Reload
+}
+
+func readBlueprintFile(path string) (map[string]*jsonBlueprint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return parseBlueprintFile(path, data)
+}
+
+func parseBlueprintFile(path string, data []byte) (map[string]*jsonBlueprint, error) {
+	var raw map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	blueprints := make(map[string]*jsonBlueprint, len(raw))
+	for name, fields := range raw {
+		bp := &jsonBlueprint{source: path, components: make(BlueprintData, len(fields))}
+		for key, value := range fields {
+			if key == extendsKey {
+				extends, err := parseExtends(value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse %s: blueprint %s: %w", path, name, err)
+				}
+				bp.extends = extends
+				continue
+			}
+			if key == removeKey {
+				continue
+			}
+			var params map[string]interface{}
+			if err := json.Unmarshal(value, &params); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: blueprint %s: component %s: %w", path, name, key, err)
+			}
+			if params == nil {
+				params = map[string]interface{}{} // null: the component with its defaults
+			}
+			bp.components[key] = params
+		}
+		if value, ok := fields[removeKey]; ok {
+			var removed []string
+			if err := json.Unmarshal(value, &removed); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: blueprint %s: %q must be a list of component names", path, name, removeKey)
+			}
+			for _, comp := range removed {
+				if _, ok := bp.components[comp]; ok {
+					return nil, fmt.Errorf("failed to parse %s: blueprint %s: component %s is both defined and removed", path, name, comp)
+				}
+				bp.components[comp] = nil
+			}
+		}
+		blueprints[name] = bp
+	}
+	return blueprints, nil
+}
+
+// parseExtends accepts either a single blueprint name or a list of names.
+func parseExtends(value json.RawMessage) ([]string, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(value), []byte(`"`)) {
+		var parent string
+		if err := json.Unmarshal(value, &parent); err != nil {
+			return nil, err
+		}
+		return []string{parent}, nil
+	}
+	var parents []string
+	if err := json.Unmarshal(value, &parents); err != nil {
+		return nil, fmt.Errorf("%q must be a blueprint name or a list of names", extendsKey)
+	}
+	return parents, nil
+}
+
+// ResolvedBlueprint returns a copy of the named blueprint with inheritance
+// applied, as it would be used by Create.
+func (f *JSONFactory) ResolvedBlueprint(name string) (BlueprintData, error) {
+	bp, err := f.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return bp.merged(nil), nil
+}
+
+// resolve returns the flattened blueprint, caching it until the next load.
+// The result is shared and must not be modified.
+func (f *JSONFactory) resolve(name string) (BlueprintData, error) {
+	f.mu.RLock()
+	bp, ok := f.resolved[name]
+	f.mu.RUnlock()
+	if ok {
+		return bp, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.resolveLocked(name, nil)
+}
+
+func (f *JSONFactory) resolveLocked(name string, chain []string) (BlueprintData, error) {
+	if bp, ok := f.resolved[name]; ok {
+		return bp, nil
+	}
+	for i, visiting := range chain {
+		if visiting == name {
+			return nil, fmt.Errorf("blueprint inheritance cycle: %s -> %s", strings.Join(chain[i:], " -> "), name)
+		}
+	}
+	def, ok := f.blueprints[name]
+	if !ok {
+		if len(chain) > 0 {
+			return nil, fmt.Errorf("blueprint %s extends unknown blueprint %s", chain[len(chain)-1], name)
+		}
+		return nil, fmt.Errorf("no blueprint found: %s", name)
+	}
+
+	chain = append(chain, name)
+	result := BlueprintData{}
+	for _, parent := range def.extends {
+		parentBP, err := f.resolveLocked(parent, chain)
+		if err != nil {
+			return nil, err
+		}
+		result = result.merged(parentBP)
+	}
+	result = result.merged(def.components)
+	f.resolved[name] = result
+	return result, nil
+}
+
+// merged returns a deep copy of b with overrides deep-merged on top. A nil
+// parameter map in overrides removes the component.
+func (b BlueprintData) merged(overrides BlueprintData) BlueprintData {
+	out := make(BlueprintData, len(b)+len(overrides))
+	for comp, params := range b {
+		out[comp] = mergeParams(nil, params)
+	}
+	for comp, params := range overrides {
+		if params == nil {
+			delete(out, comp)
+			continue
+		}
+		out[comp] = mergeParams(out[comp], params)
+	}
+	return out
+}
+
+// mergeParams returns a deep copy of dst with src merged in. Nested objects
+// merge key by key; any other value in src replaces the one in dst.
+func mergeParams(dst, src map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(dst)+len(src))
+	for k, v := range dst {
+		out[k] = copyValue(v)
+	}
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := out[k].(map[string]interface{}); ok {
+				out[k] = mergeParams(dstMap, srcMap)
+				continue
+			}
+		}
+		out[k] = copyValue(v)
+	}
+	return out
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return mergeParams(nil, v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = copyValue(item)
+		}
+		return out
+	}
+	return v
+}
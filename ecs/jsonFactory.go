@@ -5,21 +5,31 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 // ComponentConstructor is a function that creates a new zero-value instance of a component.
 type ComponentConstructor func() Component
 
 // JSONFactory manages JSON-based entity blueprints and component construction.
+// Blueprints may inherit from other blueprints through an "extends" field; see
+// LoadBlueprintsFromFile. Loading and creating are safe for concurrent use;
+// register components before use.
 type JSONFactory struct {
-	blueprints map[string]map[string]map[string]interface{}
+	mu         sync.RWMutex
+	blueprints map[string]*jsonBlueprint
+	resolved   map[string]BlueprintData        // flattened blueprints, rebuilt lazily after each load
+	prototypes map[string][]componentPrototype // decoded components for EntityPool, rebuilt likewise
 	registry   map[string]ComponentConstructor
+	sources    []blueprintSource // files and dirs loaded so far, replayed by Reload
 }
 
 // NewJSONFactory creates a new JSON-based entity factory.
 func NewJSONFactory() *JSONFactory {
 	return &JSONFactory{
-		blueprints: make(map[string]map[string]map[string]interface{}),
+		blueprints: make(map[string]*jsonBlueprint),
+		resolved:   make(map[string]BlueprintData),
+		prototypes: make(map[string][]componentPrototype),
 		registry:   make(map[string]ComponentConstructor),
 	}
 }
@@ -34,72 +44,107 @@ func (f *JSONFactory) RegisterComponent(name string, constructor ComponentConstr
 // recursing into subdirectories up to 3 levels deep.
 // Each file should contain a map of blueprint names to component definitions.
 func (f *JSONFactory) LoadBlueprintsFromDir(dir string) error {
-	return f.loadBlueprintsFromDirDepth(dir, 0)
+	files, err := blueprintFilesInDir(dir, 0)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if err := f.loadBlueprintFile(path); err != nil {
+			return err
+		}
+	}
+	f.addSource(blueprintSource{path: dir, dir: true})
+	return nil
 }
 
-func (f *JSONFactory) loadBlueprintsFromDirDepth(dir string, depth int) error {
+// blueprintFilesInDir lists the .json files LoadBlueprintsFromDir loads from dir.
+func blueprintFilesInDir(dir string, depth int) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return fmt.Errorf("failed to read blueprint dir %s: %w", dir, err)
+		return nil, fmt.Errorf("failed to read blueprint dir %s: %w", dir, err)
 	}
 
+	var files []string
 	for _, entry := range entries {
 		path := filepath.Join(dir, entry.Name())
 		if entry.IsDir() {
 			if depth < 3 {
-				if err := f.loadBlueprintsFromDirDepth(path, depth+1); err != nil {
-					return err
+				sub, err := blueprintFilesInDir(path, depth+1)
+				if err != nil {
+					return nil, err
 				}
+				files = append(files, sub...)
 			}
 			continue
 		}
 		if filepath.Ext(entry.Name()) != ".json" {
 			continue
 		}
-		if err := f.LoadBlueprintsFromFile(path); err != nil {
-			return err
-		}
+		files = append(files, path)
 	}
-	return nil
+	return files, nil
 }
 
 // LoadBlueprintsFromFile loads blueprints from a single JSON file.
 // The file should contain a map where keys are blueprint names and values
 // are maps of component names to component data.
 //
+// A blueprint may list parent blueprints under "extends" (a name or a list of
+// names). Parents are merged in order, later parents overriding earlier ones,
+// then the blueprint's own components are deep-merged on top: nested objects
+// merge key by key, any other value replaces the inherited one. Inherited
+// components listed under "remove" are dropped; a component set to null is
+// kept with its default values. Parents may live in other files.
+//
 // Example JSON:
 //
 //	{
-//	    "player": {
-//	        "HealthComponent": {"MaxHealth": 100, "Health": 100},
-//	        "AppearanceComponent": {"SpriteName": "player_idle"}
+//	    "goblin": {
+//	        "HealthComponent": {"MaxHealth": 30, "Health": 30},
+//	        "AppearanceComponent": {"SpriteName": "goblin"}
+//	    },
+//	    "goblin_archer": {
+//	        "extends": ["goblin"],
+//	        "remove": ["MeleeAttackComponent"],
+//	        "AppearanceComponent": {"SpriteName": "goblin_archer"},
+//	        "RangedAttackComponent": {"Range": 6}
 //	    }
 //	}
 func (f *JSONFactory) LoadBlueprintsFromFile(path string) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", path, err)
+	if err := f.loadBlueprintFile(path); err != nil {
+		return err
 	}
+	f.addSource(blueprintSource{path: path})
+	return nil
+}
 
-	var fileBPs map[string]map[string]map[string]interface{}
-	if err := json.Unmarshal(data, &fileBPs); err != nil {
-		return fmt.Errorf("failed to parse %s: %w", path, err)
+func (f *JSONFactory) loadBlueprintFile(path string) error {
+	fileBPs, err := readBlueprintFile(path)
+	if err != nil {
+		return err
 	}
 
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	for name, bp := range fileBPs {
 		f.blueprints[name] = bp
 	}
+	f.invalidateLocked()
 	return nil
 }
 
 // BlueprintExists checks if a blueprint name is registered.
 func (f *JSONFactory) BlueprintExists(name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	_, ok := f.blueprints[name]
 	return ok
 }
 
 // GetBlueprintNames returns all registered blueprint names.
 func (f *JSONFactory) GetBlueprintNames() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	names := make([]string, 0, len(f.blueprints))
 	for name := range f.blueprints {
 		names = append(names, name)
@@ -111,9 +156,25 @@ func (f *JSONFactory) GetBlueprintNames() []string {
 // Components are created by looking up their constructors in the registry,
 // then populating them via JSON unmarshalling.
 func (f *JSONFactory) Create(name string) (*Entity, error) {
-	blueprint, ok := f.blueprints[name]
-	if !ok {
-		return nil, fmt.Errorf("no blueprint found: %s", name)
+	return f.CreateWithOverrides(name, nil)
+}
+
+// CreateWithOverrides creates an entity from a named blueprint after
+// deep-merging overrides (component name -> parameters) over the resolved
+// blueprint, e.g. so a level file can tweak a single field of one instance.
+// Overrides may add components the blueprint lacks; a nil parameter map
+// removes the component.
+//
+//	e, err := factory.CreateWithOverrides("goblin", ecs.BlueprintData{
+//	    "HealthComponent": {"Health": 5},
+//	})
+func (f *JSONFactory) CreateWithOverrides(name string, overrides BlueprintData) (*Entity, error) {
+	blueprint, err := f.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if overrides != nil {
+		blueprint = blueprint.merged(overrides)
 	}
 
 	entity := &Entity{}
@@ -133,9 +194,9 @@ func (f *JSONFactory) Create(name string) (*Entity, error) {
 // CreateWithCallback creates an entity and calls the callback for each component
 // before adding it to the entity. This allows custom initialization.
 func (f *JSONFactory) CreateWithCallback(name string, callback func(comp Component) error) (*Entity, error) {
-	blueprint, ok := f.blueprints[name]
-	if !ok {
-		return nil, fmt.Errorf("no blueprint found: %s", name)
+	blueprint, err := f.resolve(name)
+	if err != nil {
+		return nil, err
 	}
 
 	entity := &Entity{}
@@ -0,0 +1,212 @@
+package ecs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statsComponent struct {
+	Health int
+	Speed  float64
+	Tags   []string
+	Loot   map[string]int
+}
+
+func (c *statsComponent) GetType() ComponentType { return "Stats" }
+
+type bowComponent struct{ Range int }
+
+func (c *bowComponent) GetType() ComponentType { return "Bow" }
+
+type armorComponent struct{ Value int }
+
+func (c *armorComponent) GetType() ComponentType { return "Armor" }
+
+func writeBlueprints(t testing.TB, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func newTestFactory(t testing.TB, files map[string]string) *JSONFactory {
+	t.Helper()
+	f, _ := newTestFactoryDir(t, files)
+	return f
+}
+
+// newTestFactoryDir is newTestFactory that also returns the blueprint directory.
+func newTestFactoryDir(t testing.TB, files map[string]string) (*JSONFactory, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		writeBlueprints(t, dir, name, content)
+	}
+	f := NewJSONFactory()
+	f.RegisterComponent("Stats", func() Component { return &statsComponent{} })
+	f.RegisterComponent("Bow", func() Component { return &bowComponent{} })
+	f.RegisterComponent("Armor", func() Component { return &armorComponent{} })
+	assert.Nil(t, f.LoadBlueprintsFromDir(dir))
+	return f, dir
+}
+
+func TestJSONFactoryExtendsDeepMerges(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, map[string]string{
+		"base.json": `{
+			"goblin": {
+				"Stats": {"Health": 30, "Speed": 1.5, "Tags": ["green"], "Loot": {"gold": 2, "rag": 1}},
+				"Armor": {"Value": 1}
+			},
+			"archer": {"Bow": {"Range": 4}}
+		}`,
+		"derived.json": `{
+			"goblin_archer": {
+				"extends": ["goblin", "archer"],
+				"Stats": {"Speed": 2, "Tags": ["green", "ranged"], "Loot": {"arrow": 5}},
+				"remove": ["Armor"]
+			},
+			"goblin_sniper": {
+				"extends": "goblin_archer",
+				"Bow": {"Range": 9}
+			}
+		}`,
+	})
+
+	e, err := f.Create("goblin_sniper")
+
+	assert.Nil(err)
+	assert.Equal("goblin_sniper", e.Blueprint)
+	stats := e.GetComponent("Stats").(*statsComponent)
+	assert.Equal(30, stats.Health, "Expected inherited field")
+	assert.Equal(2.0, stats.Speed, "Expected overridden field")
+	assert.Equal([]string{"green", "ranged"}, stats.Tags, "Expected lists to be replaced, not merged")
+	assert.Equal(map[string]int{"gold": 2, "rag": 1, "arrow": 5}, stats.Loot, "Expected nested objects to merge")
+	assert.Equal(9, e.GetComponent("Bow").(*bowComponent).Range)
+	assert.False(e.HasComponent("Armor"), "Expected the inherited component to be removed")
+
+	goblin, err := f.Create("goblin")
+	assert.Nil(err)
+	assert.Equal(1.5, goblin.GetComponent("Stats").(*statsComponent).Speed, "Expected parent to be unaffected")
+	assert.Equal(map[string]int{"gold": 2, "rag": 1}, goblin.GetComponent("Stats").(*statsComponent).Loot)
+}
+
+func TestJSONFactoryRemovalIsExplicit(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, map[string]string{"units.json": `{
+		"goblin": {"Armor": {"Value": 1}, "Bow": {"Range": 4}},
+		"bare_goblin": {"extends": "goblin", "remove": ["Bow"], "Armor": null}
+	}`})
+
+	e, err := f.Create("bare_goblin")
+	assert.Nil(err)
+	assert.False(e.HasComponent("Bow"))
+	assert.Equal(&armorComponent{Value: 1}, e.GetComponent("Armor"), "Expected null to keep the inherited component")
+
+	var buf bytes.Buffer
+	assert.Nil(f.WriteBlueprints(&buf, "bare_goblin"))
+	assert.Contains(buf.String(), `"remove": [`, "Expected removals written back as a list")
+
+	dir := t.TempDir()
+	conflict := writeBlueprints(t, dir, "conflict.json", `{"x": {"remove": ["Bow"], "Bow": {}}}`)
+	assert.ErrorContains(NewJSONFactory().LoadBlueprintsFromFile(conflict), "component Bow is both defined and removed")
+	bad := writeBlueprints(t, dir, "bad.json", `{"x": {"remove": "Bow"}}`)
+	assert.ErrorContains(NewJSONFactory().LoadBlueprintsFromFile(bad), `"remove" must be a list of component names`)
+}
+
+func TestJSONFactoryCreateWithOverrides(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, map[string]string{
+		"units.json": `{"goblin": {"Stats": {"Health": 30, "Speed": 1.5}, "Armor": {"Value": 1}}}`,
+	})
+
+	e, err := f.CreateWithOverrides("goblin", BlueprintData{
+		"Stats": {"Health": 5},
+		"Bow":   {"Range": 2},
+		"Armor": nil,
+	})
+
+	assert.Nil(err)
+	stats := e.GetComponent("Stats").(*statsComponent)
+	assert.Equal(5, stats.Health)
+	assert.Equal(1.5, stats.Speed)
+	assert.Equal(2, e.GetComponent("Bow").(*bowComponent).Range)
+	assert.False(e.HasComponent("Armor"))
+
+	plain, err := f.Create("goblin")
+	assert.Nil(err)
+	assert.Equal(30, plain.GetComponent("Stats").(*statsComponent).Health, "Expected overrides not to leak into the blueprint")
+}
+
+func TestJSONFactoryExtendsErrors(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, map[string]string{
+		"units.json": `{
+			"a": {"extends": "b"},
+			"b": {"extends": ["a"]},
+			"orphan": {"extends": "missing"}
+		}`,
+	})
+
+	_, err := f.Create("a")
+	assert.EqualError(err, "blueprint inheritance cycle: a -> b -> a")
+
+	_, err = f.Create("orphan")
+	assert.EqualError(err, "blueprint orphan extends unknown blueprint missing")
+
+	_, err = f.Create("nobody")
+	assert.EqualError(err, "no blueprint found: nobody")
+}
+
+func TestJSONFactoryRejectsMalformedExtends(t *testing.T) {
+	dir := t.TempDir()
+	path := writeBlueprints(t, dir, "bad.json", `{"a": {"extends": 3}}`)
+
+	err := NewJSONFactory().LoadBlueprintsFromFile(path)
+
+	assert.ErrorContains(t, err, `"extends" must be a blueprint name or a list of names`)
+}
+
+func TestJSONFactoryValidateReportsEveryProblem(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	units := writeBlueprints(t, dir, "units.json", `{
+		"goblin": {
+			"Stats": {"Helth": 30, "Speed": "fast", "Loot": {"gold": "lots"}},
+			"Wings": {}
+		},
+		"goblin_archer": {"extends": "goblin", "Bow": {"Range": 4}, "remove": ["Armor"]},
+		"orphan": {"extends": "missing"}
+	}`)
+	f := NewJSONFactory()
+	f.RegisterComponent("Stats", func() Component { return &statsComponent{} })
+	f.RegisterComponent("Bow", func() Component { return &bowComponent{} })
+	assert.Nil(f.LoadBlueprintsFromFile(units))
+
+	err := f.Validate()
+
+	var errs ValidationErrors
+	assert.ErrorAs(err, &errs)
+	assert.Len(errs, 5)
+	assert.Equal(units+": blueprint goblin: component Stats: field Helth: unknown field", errs[0].Error())
+	assert.ErrorIs(errs[0], ErrUnknownField)
+	assert.Equal("Loot.gold", errs[1].Field)
+	assert.Equal("cannot use JSON string as int", errs[1].Err.Error())
+	assert.Equal("Speed", errs[2].Field)
+	assert.Equal("Wings", errs[3].Component)
+	assert.ErrorIs(errs[3], ErrUnknownComponent)
+	assert.Equal("orphan", errs[4].Blueprint)
+	assert.Equal("", errs[4].Component)
+}
+
+func TestJSONFactoryValidatePassesCleanBlueprints(t *testing.T) {
+	f := newTestFactory(t, map[string]string{
+		"units.json": `{"goblin": {"Stats": {"health": 30, "Tags": ["a"]}}, "boss": {"extends": "goblin", "Armor": {"Value": 3}}}`,
+	})
+
+	assert.Nil(t, f.Validate())
+}
@@ -0,0 +1,326 @@
+package ecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/mechanical-lich/mlge/event"
+)
+
+const EventTypeBlueprintsReloaded event.EventType = "ecs_blueprints_reloaded"
+
+// BlueprintsReloadedEvent is sent by BlueprintWatcher after a successful
+// reload. Blueprints lists, sorted, every blueprint whose resolved components
+// changed, including ones that were added, removed or inherit from a changed
+// parent.
+type BlueprintsReloadedEvent struct {
+	Blueprints []string
+}
+
+func (e BlueprintsReloadedEvent) GetType() event.EventType {
+	return EventTypeBlueprintsReloaded
+}
+
+// blueprintSource is a file or directory passed to one of the Load methods.
+type blueprintSource struct {
+	path string
+	dir  bool
+}
+
+func (f *JSONFactory) addSource(src blueprintSource) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !slices.Contains(f.sources, src) {
+		f.sources = append(f.sources, src)
+	}
+}
+
+// sourceFiles lists the files the loaded sources currently expand to, in load order.
+func (f *JSONFactory) sourceFiles() ([]string, error) {
+	f.mu.RLock()
+	sources := slices.Clone(f.sources)
+	f.mu.RUnlock()
+
+	var files []string
+	for _, src := range sources {
+		if !src.dir {
+			files = append(files, src.path)
+			continue
+		}
+		dirFiles, err := blueprintFilesInDir(src.path, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+// Reload re-reads every file and directory previously loaded, picking up new
+// and deleted files in loaded directories, and returns the sorted names of the
+// blueprints whose resolved components changed. The swap is atomic: if any
+// file fails to read or parse, the current blueprints are kept and the error
+// is returned.
+//
+// If w is non-nil, live entities in w created from a changed blueprint are
+// updated to match it. Only parameters whose default changed are written, so
+// runtime state such as current health survives a reload: each affected
+// component is rebuilt from its current value with the new defaults merged
+// in and set with AddComponent, firing OnChanged observers. Components added
+// to a blueprint are created on its entities and components removed from it
+// are removed. Call Reload from the goroutine that owns w.
+func (f *JSONFactory) Reload(w *World) ([]string, error) {
+	changed, before, err := f.swapBlueprints()
+	if err != nil || w == nil {
+		return changed, err
+	}
+	return changed, f.reapplyBlueprints(w, changed, before)
+}
+
+// swapBlueprints re-reads the loaded sources and replaces the blueprint set,
+// returning the changed names and the resolved blueprints from before the swap.
+func (f *JSONFactory) swapBlueprints() ([]string, map[string]BlueprintData, error) {
+	files, err := f.sourceFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	next := make(map[string]*jsonBlueprint)
+	f.mu.RLock()
+	for name, bp := range f.blueprints {
+		if bp.imported {
+			next[name] = bp
+		}
+	}
+	f.mu.RUnlock()
+	for _, path := range files {
+		fileBPs, err := readBlueprintFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("blueprint reload failed, keeping previous blueprints: %w", err)
+		}
+		for name, bp := range fileBPs {
+			next[name] = bp
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	before := resolveAll(f.blueprints)
+	after := resolveAll(next)
+	f.blueprints = next
+	f.invalidateLocked()
+
+	var changed []string
+	for name, bp := range after {
+		if old, ok := before[name]; !ok || !reflect.DeepEqual(old, bp) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed, before, nil
+}
+
+// resolveAll flattens every blueprint in blueprints. Blueprints that fail to
+// resolve map to nil, so fixing or breaking one still counts as a change.
+func resolveAll(blueprints map[string]*jsonBlueprint) map[string]BlueprintData {
+	tmp := &JSONFactory{blueprints: blueprints, resolved: make(map[string]BlueprintData)}
+	out := make(map[string]BlueprintData, len(blueprints))
+	for name := range blueprints {
+		bp, _ := tmp.resolveLocked(name, nil)
+		out[name] = bp
+	}
+	return out
+}
+
+// reapplyBlueprints updates the entities in w created from the named
+// blueprints, given their resolved definitions from before the reload.
+func (f *JSONFactory) reapplyBlueprints(w *World, names []string, before map[string]BlueprintData) error {
+	for _, name := range names {
+		after, err := f.resolve(name)
+		if err != nil {
+			continue // removed or broken; leave its entities alone
+		}
+		old := before[name]
+		for _, e := range w.Entities() {
+			if e.Blueprint != name {
+				continue
+			}
+			if err := f.reapply(e, old, after); err != nil {
+				return fmt.Errorf("failed to reapply blueprint %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *JSONFactory) reapply(e *Entity, before, after BlueprintData) error {
+	for compName, params := range after {
+		oldParams, existed := before[compName]
+		t, ok := f.componentType(compName)
+		if !ok {
+			return fmt.Errorf("component %s not registered", compName)
+		}
+		current := e.GetComponent(t)
+		if current == nil {
+			if existed {
+				continue // removed from this entity at runtime; keep it that way
+			}
+			comp, err := f.CreateComponent(compName, params)
+			if err != nil {
+				return err
+			}
+			e.AddComponent(comp)
+			continue
+		}
+		diff := changedParams(oldParams, params)
+		if len(diff) == 0 {
+			continue
+		}
+		raw, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		var values map[string]interface{}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return err
+		}
+		comp, err := f.CreateComponent(compName, mergeParams(values, diff))
+		if err != nil {
+			return err
+		}
+		e.AddComponent(comp)
+	}
+	for compName := range before {
+		if _, ok := after[compName]; ok {
+			continue
+		}
+		if t, ok := f.componentType(compName); ok {
+			e.RemoveComponent(t)
+		}
+	}
+	return nil
+}
+
+// componentType returns the type of the component registered under name,
+// which need not equal name.
+func (f *JSONFactory) componentType(name string) (ComponentType, bool) {
+	constructor, ok := f.registry[name]
+	if !ok {
+		return "", false
+	}
+	return constructor().GetType(), true
+}
+
+// changedParams returns the parameters in after whose values differ from
+// before, descending into nested objects.
+func changedParams(before, after map[string]interface{}) map[string]interface{} {
+	diff := make(map[string]interface{})
+	for k, v := range after {
+		old, ok := before[k]
+		if ok && reflect.DeepEqual(old, v) {
+			continue
+		}
+		if vMap, ok := v.(map[string]interface{}); ok {
+			if oldMap, ok := old.(map[string]interface{}); ok {
+				diff[k] = changedParams(oldMap, vMap)
+				continue
+			}
+		}
+		diff[k] = v
+	}
+	return diff
+}
+
+// BlueprintWatcher polls the files and directories loaded into a JSONFactory
+// and reloads them when they change, so designers can edit blueprints while
+// the game runs. Call Poll from the game loop, e.g. in Update; it does nothing
+// until Interval has passed since the last check.
+//
+//	watcher := factory.NewWatcher(time.Second)
+//	watcher.World = world
+//	watcher.Send = event.GetQueuedInstance().QueueEvent
+//	...
+//	if _, err := watcher.Poll(); err != nil {
+//	    log.Println(err)
+//	}
+type BlueprintWatcher struct {
+	Factory  *JSONFactory
+	Interval time.Duration
+
+	// World, if set, is passed to JSONFactory.Reload so entities from changed
+	// blueprints are updated.
+	World *World
+	// Send, if set, receives a BlueprintsReloadedEvent after each reload
+	// that changed at least one blueprint.
+	Send func(event.EventData)
+
+	lastCheck time.Time
+	stamps    map[string]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher creates a BlueprintWatcher for f that checks for changes at
+// most once per interval. The current files are taken as the baseline.
+func (f *JSONFactory) NewWatcher(interval time.Duration) *BlueprintWatcher {
+	w := &BlueprintWatcher{Factory: f, Interval: interval, lastCheck: time.Now()}
+	w.stamps, _ = w.scan()
+	return w
+}
+
+// Poll reloads the factory if any blueprint file was modified, added or
+// removed since the last check, and returns the names of the blueprints that
+// changed. A file that fails to parse leaves the previous blueprints in place
+// and is reported once; it is retried when it changes again.
+func (w *BlueprintWatcher) Poll() ([]string, error) {
+	if time.Since(w.lastCheck) < w.Interval {
+		return nil, nil
+	}
+	w.lastCheck = time.Now()
+
+	stamps, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	if maps.Equal(stamps, w.stamps) {
+		return nil, nil
+	}
+	w.stamps = stamps
+
+	changed, err := w.Factory.Reload(w.World)
+	if err != nil {
+		return changed, err
+	}
+	if len(changed) > 0 && w.Send != nil {
+		w.Send(BlueprintsReloadedEvent{Blueprints: changed})
+	}
+	return changed, nil
+}
+
+func (w *BlueprintWatcher) scan() (map[string]fileStamp, error) {
+	files, err := w.Factory.sourceFiles()
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]fileStamp, len(files))
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // a missing explicit file is reported by Reload
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
@@ -0,0 +1,151 @@
+package ecs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mechanical-lich/mlge/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFactoryReloadReportsChangedBlueprints(t *testing.T) {
+	assert := assert.New(t)
+	f, dir := newTestFactoryDir(t, map[string]string{
+		"base.json": `{
+			"goblin": {"Stats": {"Health": 30}},
+			"rock": {"Armor": {"Value": 9}}
+		}`,
+		"derived.json": `{"goblin_archer": {"extends": "goblin", "Bow": {"Range": 4}}}`,
+	})
+
+	writeBlueprints(t, dir, "base.json", `{
+		"goblin": {"Stats": {"Health": 40}},
+		"rock": {"Armor": {"Value": 9}}
+	}`)
+	writeBlueprints(t, dir, "new.json", `{"troll": {"Stats": {"Health": 90}}}`)
+
+	changed, err := f.Reload(nil)
+	assert.Nil(err)
+	assert.Equal([]string{"goblin", "goblin_archer", "troll"}, changed, "Expected children of changed parents and new blueprints")
+
+	bp, err := f.ResolvedBlueprint("goblin_archer")
+	assert.Nil(err)
+	assert.Equal(float64(40), bp["Stats"]["Health"])
+
+	assert.Nil(os.Remove(filepath.Join(dir, "new.json")))
+	changed, err = f.Reload(nil)
+	assert.Nil(err)
+	assert.Equal([]string{"troll"}, changed, "Expected deleted blueprints to be reported")
+	assert.False(f.BlueprintExists("troll"))
+}
+
+func TestJSONFactoryReloadKeepsOldSetOnParseError(t *testing.T) {
+	assert := assert.New(t)
+	f, dir := newTestFactoryDir(t, map[string]string{
+		"a.json": `{"goblin": {"Stats": {"Health": 30}}}`,
+		"b.json": `{"rock": {"Armor": {"Value": 9}}}`,
+	})
+
+	writeBlueprints(t, dir, "a.json", `{"goblin": {"Stats": {"Health": 50}}}`)
+	writeBlueprints(t, dir, "b.json", `{"rock": {`)
+
+	changed, err := f.Reload(nil)
+	assert.NotNil(err)
+	assert.Empty(changed)
+	bp, err := f.ResolvedBlueprint("goblin")
+	assert.Nil(err)
+	assert.Equal(float64(30), bp["Stats"]["Health"], "Expected valid files not to be applied on their own")
+	assert.True(f.BlueprintExists("rock"))
+}
+
+func TestJSONFactoryReloadReappliesChangedDefaults(t *testing.T) {
+	assert := assert.New(t)
+	f, dir := newTestFactoryDir(t, map[string]string{
+		"units.json": `{
+			"goblin": {"Stats": {"Health": 30, "Speed": 1.5}, "Armor": {"Value": 1}},
+			"rock": {"Armor": {"Value": 9}}
+		}`,
+	})
+	w := NewWorld()
+	goblin, err := f.Create("goblin")
+	assert.Nil(err)
+	w.Add(goblin)
+	goblin.GetComponent("Stats").(*statsComponent).Health = 12 // damaged at runtime
+	rock, err := f.Create("rock")
+	assert.Nil(err)
+	w.Add(rock)
+	oldRockArmor := rock.GetComponent("Armor")
+
+	changedEvents := 0
+	w.OnChanged("Stats", func(e *Entity, c Component) { changedEvents++ })
+
+	writeBlueprints(t, dir, "units.json", `{
+		"goblin": {"Stats": {"Health": 30, "Speed": 2.5}, "Bow": {"Range": 3}},
+		"rock": {"Armor": {"Value": 9}}
+	}`)
+	changed, err := f.Reload(w)
+	assert.Nil(err)
+	assert.Equal([]string{"goblin"}, changed)
+
+	stats := goblin.GetComponent("Stats").(*statsComponent)
+	assert.Equal(2.5, stats.Speed, "Expected changed default to be applied")
+	assert.Equal(12, stats.Health, "Expected runtime state of unchanged defaults to survive")
+	assert.Equal(1, changedEvents)
+	assert.True(goblin.HasComponent("Bow"), "Expected component added to the blueprint to be created")
+	assert.False(goblin.HasComponent("Armor"), "Expected component dropped from the blueprint to be removed")
+	assert.Same(oldRockArmor, rock.GetComponent("Armor"), "Expected unchanged blueprints to be left alone")
+}
+
+func TestJSONFactoryReloadResolvesRegistryNames(t *testing.T) {
+	assert := assert.New(t)
+	f, dir := newTestFactoryDir(t, nil)
+	f.RegisterComponent("stats", func() Component { return &statsComponent{} })
+	f.RegisterComponent("armor", func() Component { return &armorComponent{} })
+	writeBlueprints(t, dir, "units.json", `{"goblin": {"stats": {"Health": 30, "Speed": 1.5}, "armor": {"Value": 1}}}`)
+	_, err := f.Reload(nil)
+	assert.Nil(err)
+
+	w := NewWorld()
+	goblin, err := f.Create("goblin")
+	assert.Nil(err)
+	w.Add(goblin)
+	goblin.GetComponent("Stats").(*statsComponent).Health = 12
+
+	writeBlueprints(t, dir, "units.json", `{"goblin": {"stats": {"Health": 30, "Speed": 2.5}}}`)
+	_, err = f.Reload(w)
+	assert.Nil(err)
+
+	stats := goblin.GetComponent("Stats").(*statsComponent)
+	assert.Equal(2.5, stats.Speed, "Expected components registered under another name to be updated")
+	assert.Equal(12, stats.Health)
+	assert.False(goblin.HasComponent("Armor"), "Expected components registered under another name to be removed")
+}
+
+func TestBlueprintWatcherPoll(t *testing.T) {
+	assert := assert.New(t)
+	f, dir := newTestFactoryDir(t, map[string]string{
+		"units.json": `{"goblin": {"Stats": {"Health": 30}}}`,
+	})
+	watcher := f.NewWatcher(0)
+	var sent []event.EventData
+	watcher.Send = func(e event.EventData) { sent = append(sent, e) }
+
+	changed, err := watcher.Poll()
+	assert.Nil(err)
+	assert.Empty(changed, "Expected no reload without file changes")
+
+	writeBlueprints(t, dir, "units.json", `{"goblin": {"Stats": {"Health": 300}}}`)
+	changed, err = watcher.Poll()
+	assert.Nil(err)
+	assert.Equal([]string{"goblin"}, changed)
+	assert.Equal([]event.EventData{BlueprintsReloadedEvent{Blueprints: []string{"goblin"}}}, sent)
+
+	writeBlueprints(t, dir, "units.json", `{"goblin": `)
+	_, err = watcher.Poll()
+	assert.NotNil(err)
+	_, err = watcher.Poll()
+	assert.Nil(err, "Expected a broken file to be reported once")
+	assert.True(f.BlueprintExists("goblin"))
+	assert.Len(sent, 1)
+}
@@ -0,0 +1,162 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnknownComponent is reported for components with no registered constructor.
+	ErrUnknownComponent = errors.New("component not registered")
+	// ErrUnknownField is reported for parameters that match no field of the component.
+	ErrUnknownField = errors.New("unknown field")
+)
+
+// ValidationError describes one problem found by JSONFactory.Validate.
+type ValidationError struct {
+	File      string // blueprint file the problem was written in
+	Blueprint string
+	Component string // empty for blueprint-level problems such as inheritance cycles
+	Field     string // dotted path of the offending parameter, if any
+	Err       error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	b.WriteString(": blueprint ")
+	b.WriteString(e.Blueprint)
+	if e.Component != "" {
+		b.WriteString(": component ")
+		b.WriteString(e.Component)
+	}
+	if e.Field != "" {
+		b.WriteString(": field ")
+		b.WriteString(e.Field)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is every problem found by one Validate call, sorted by
+// file, blueprint, component and field.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks every loaded blueprint against the registered component
+// constructors without creating entities. It reports unknown components,
+// parameters that match no component field, parameters whose JSON type does
+// not fit the field, unknown parents and inheritance cycles.
+//
+// Each component's parameters are checked in the blueprint that writes them,
+// so problems point at the file to fix. Unlike Create, decoding is strict.
+// Returns nil or a ValidationErrors listing every problem.
+func (f *JSONFactory) Validate() error {
+	f.mu.RLock()
+	names := make([]string, 0, len(f.blueprints))
+	for name := range f.blueprints {
+		names = append(names, name)
+	}
+	f.mu.RUnlock()
+
+	var errs ValidationErrors
+	for _, name := range names {
+		f.mu.RLock()
+		def := f.blueprints[name]
+		f.mu.RUnlock()
+		if _, err := f.resolve(name); err != nil {
+			errs = append(errs, &ValidationError{File: def.source, Blueprint: name, Err: err})
+		}
+		for compName, params := range def.components {
+			if params == nil {
+				continue // removal of an inherited component
+			}
+			for _, problem := range f.validateComponent(compName, params) {
+				problem.File = def.source
+				problem.Blueprint = name
+				errs = append(errs, problem)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		a, b := errs[i], errs[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Blueprint != b.Blueprint {
+			return a.Blueprint < b.Blueprint
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.Field < b.Field
+	})
+	return errs
+}
+
+// validateComponent strictly decodes each parameter on its own so that every
+// bad field is reported, not just the first.
+func (f *JSONFactory) validateComponent(name string, params map[string]interface{}) []*ValidationError {
+	constructor, ok := f.registry[name]
+	if !ok {
+		return []*ValidationError{{Component: name, Err: ErrUnknownComponent}}
+	}
+
+	var problems []*ValidationError
+	for field, value := range params {
+		raw, err := json.Marshal(map[string]interface{}{field: value})
+		if err != nil {
+			problems = append(problems, &ValidationError{Component: name, Field: field, Err: err})
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(constructor()); err != nil {
+			problems = append(problems, fieldProblem(name, field, err))
+		}
+	}
+	return problems
+}
+
+func fieldProblem(component, field string, err error) *ValidationError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		path := field
+		if typeErr.Field != "" {
+			path = typeErr.Field
+		}
+		return &ValidationError{
+			Component: component,
+			Field:     path,
+			Err:       fmt.Errorf("cannot use JSON %s as %s", typeErr.Value, typeErr.Type),
+		}
+	}
+	// encoding/json reports unknown fields only through the message text.
+	if unknown, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		path := strings.Trim(unknown, `"`)
+		if path != field {
+			path = field + "." + path
+		}
+		return &ValidationError{Component: component, Field: path, Err: ErrUnknownField}
+	}
+	return &ValidationError{Component: component, Field: field, Err: err}
+}
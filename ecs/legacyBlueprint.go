@@ -0,0 +1,338 @@
+package ecs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LegacyComponent describes how one component of the legacy text blueprint
+// format (see LoadFactoryFromStream) maps onto a JSONFactory component.
+type LegacyComponent struct {
+	// Name is the JSONFactory component name. Defaults to the legacy name.
+	Name string
+
+	// Fields names the JSON field each positional parameter sets, in order.
+	// Lines may give fewer parameters than fields; the rest keep their zero
+	// values.
+	Fields []string
+}
+
+// LegacyFormat maps legacy component names to their descriptors, for
+// importing and exporting the legacy text format with a JSONFactory.
+//
+//	format := ecs.LegacyFormat{
+//	    "Health":     {Name: "HealthComponent", Fields: []string{"MaxHealth", "Health"}},
+//	    "Appearance": {Name: "AppearanceComponent", Fields: []string{"SpriteName"}},
+//	}
+type LegacyFormat map[ComponentType]LegacyComponent
+
+// jsonName returns the JSONFactory name for the legacy component name.
+func (lf LegacyFormat) jsonName(legacy ComponentType) string {
+	if name := lf[legacy].Name; name != "" {
+		return name
+	}
+	return string(legacy)
+}
+
+// legacyName returns the legacy name and descriptor of a JSONFactory component.
+func (lf LegacyFormat) legacyName(name string) (ComponentType, LegacyComponent, bool) {
+	for legacy, desc := range lf {
+		if lf.jsonName(legacy) == name {
+			return legacy, desc, true
+		}
+	}
+	return "", LegacyComponent{}, false
+}
+
+// ImportLegacyBlueprintFile imports blueprints from a legacy text file; see
+// ImportLegacyBlueprints.
+func (f *JSONFactory) ImportLegacyBlueprintFile(path string, format LegacyFormat) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return f.importLegacy(path, file, format)
+}
+
+// ImportLegacyBlueprints reads blueprints in the legacy text format used by
+// LoadFactoryFromStream and adds them to the factory, returning their sorted
+// names. Each `Component:param,param` line becomes a JSON component whose
+// fields are named by format. Parameters are converted to the type of the
+// matching field of the registered component (numbers, bools or strings), so
+// register components before importing; parameters of unregistered components
+// are kept as strings. The import is all or nothing.
+//
+// Imported blueprints live only in memory: Reload keeps them as they are.
+// Write them out with WriteBlueprints to migrate the content to JSON.
+func (f *JSONFactory) ImportLegacyBlueprints(r io.Reader, format LegacyFormat) ([]string, error) {
+	return f.importLegacy("legacy blueprints", r, format)
+}
+
+func (f *JSONFactory) importLegacy(source string, r io.Reader, format LegacyFormat) ([]string, error) {
+	imported := make(map[string]*jsonBlueprint)
+	scanner := bufio.NewScanner(r)
+	name := ""
+	line := 0
+	for scanner.Scan() {
+		line++
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" {
+			name = ""
+			continue
+		}
+		if name == "" {
+			name = value
+			if imported[name] == nil {
+				imported[name] = &jsonBlueprint{source: source, imported: true, components: BlueprintData{}}
+			}
+			continue
+		}
+		compName, params, err := f.importLegacyComponent(value, format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import %s: line %d: blueprint %s: %w", source, line, name, err)
+		}
+		imported[name].components[compName] = params
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(imported))
+	for name, bp := range imported {
+		f.blueprints[name] = bp
+		names = append(names, name)
+	}
+	f.invalidateLocked()
+	slices.Sort(names)
+	return names, nil
+}
+
+// importLegacyComponent converts one `Component:param,param` line.
+func (f *JSONFactory) importLegacyComponent(line string, format LegacyFormat) (string, map[string]interface{}, error) {
+	legacy, rest, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", nil, fmt.Errorf("expected Component:params, got %q", line)
+	}
+	desc, ok := format[ComponentType(legacy)]
+	if !ok {
+		return "", nil, fmt.Errorf("no legacy descriptor for component %s", legacy)
+	}
+	var values []string
+	if rest != "" {
+		values = strings.Split(rest, ",")
+	}
+	if len(values) > len(desc.Fields) {
+		return "", nil, fmt.Errorf("component %s has %d params, descriptor names %d fields", legacy, len(values), len(desc.Fields))
+	}
+
+	name := format.jsonName(ComponentType(legacy))
+	var target reflect.Type
+	if constructor, ok := f.registry[name]; ok {
+		target = reflect.TypeOf(constructor())
+	}
+	params := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		field := desc.Fields[i]
+		v, err := legacyParam(value, jsonFieldType(target, field))
+		if err != nil {
+			return "", nil, fmt.Errorf("component %s field %s: %w", legacy, field, err)
+		}
+		params[field] = v
+	}
+	return name, params, nil
+}
+
+// legacyParam converts a positional parameter to the JSON value for a field
+// of type t. A nil t keeps the parameter as a string. An empty parameter, as
+// in `stats:,1.5`, is the zero value of a bool or number field.
+func legacyParam(value string, t reflect.Type) (interface{}, error) {
+	if t == nil {
+		return value, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if value == "" {
+			return false, nil
+		}
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if value == "" {
+			return 0.0, nil
+		}
+		return strconv.ParseFloat(value, 64)
+	}
+	return value, nil
+}
+
+// jsonFieldType returns the type of the struct field that encoding/json
+// would fill from key, or nil if t is not a struct with such a field.
+func jsonFieldType(t reflect.Type, key string) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fold reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		if tag, _, _ := strings.Cut(sf.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		if name == key {
+			return sf.Type
+		}
+		if fold == nil && strings.EqualFold(name, key) {
+			fold = sf.Type
+		}
+	}
+	return fold
+}
+
+// ExportLegacyBlueprints writes the named blueprints, or all of them when no
+// names are given, in the legacy text format, with inheritance applied.
+// Components are written in format's field order; components format does not
+// describe, nested values and parameters containing ',' or ':' cannot be
+// represented and return an error.
+func (f *JSONFactory) ExportLegacyBlueprints(w io.Writer, format LegacyFormat, names ...string) error {
+	if len(names) == 0 {
+		names = f.GetBlueprintNames()
+		slices.Sort(names)
+	}
+	bw := bufio.NewWriter(w)
+	for i, name := range names {
+		bp, err := f.resolve(name)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		bw.WriteString(name + "\n")
+
+		compNames := make([]string, 0, len(bp))
+		for compName := range bp {
+			compNames = append(compNames, compName)
+		}
+		slices.Sort(compNames)
+		for _, compName := range compNames {
+			line, err := exportLegacyComponent(compName, bp[compName], format)
+			if err != nil {
+				return fmt.Errorf("failed to export blueprint %s: %w", name, err)
+			}
+			bw.WriteString(line + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// exportLegacyComponent formats one component as a `Component:param,param` line.
+func exportLegacyComponent(name string, params map[string]interface{}, format LegacyFormat) (string, error) {
+	legacy, desc, ok := format.legacyName(name)
+	if !ok {
+		return "", fmt.Errorf("no legacy descriptor for component %s", name)
+	}
+	for key := range params {
+		if !slices.Contains(desc.Fields, key) {
+			return "", fmt.Errorf("component %s field %s has no legacy position", name, key)
+		}
+	}
+	values := make([]string, len(desc.Fields))
+	last := 0
+	for i, field := range desc.Fields {
+		v, ok := params[field]
+		if !ok {
+			continue
+		}
+		s, err := legacyString(v)
+		if err != nil {
+			return "", fmt.Errorf("component %s field %s: %w", name, field, err)
+		}
+		values[i] = s
+		last = i + 1
+	}
+	return string(legacy) + ":" + strings.Join(values[:last], ","), nil
+}
+
+func legacyString(v interface{}) (string, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case bool:
+		s = strconv.FormatBool(v)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("%T values cannot be written in the legacy format", v)
+	}
+	if strings.ContainsAny(s, ",:\n") {
+		return "", fmt.Errorf("%q contains a character the legacy format reserves", s)
+	}
+	return s, nil
+}
+
+// WriteBlueprints writes the named blueprints, or all of them when no names
+// are given, as a JSON file LoadBlueprintsFromFile can read. Blueprints are
+// written as defined, keeping their "extends" and "remove" lists, not resolved.
+func (f *JSONFactory) WriteBlueprints(w io.Writer, names ...string) error {
+	f.mu.RLock()
+	out := make(map[string]map[string]interface{}, len(f.blueprints))
+	if len(names) == 0 {
+		for name := range f.blueprints {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		bp, ok := f.blueprints[name]
+		if !ok {
+			f.mu.RUnlock()
+			return fmt.Errorf("no blueprint found: %s", name)
+		}
+		fields := make(map[string]interface{}, len(bp.components)+1)
+		var removed []string
+		for comp, params := range bp.components {
+			if params == nil {
+				removed = append(removed, comp)
+				continue
+			}
+			fields[comp] = params
+		}
+		if len(bp.extends) > 0 {
+			fields[extendsKey] = bp.extends
+		}
+		if len(removed) > 0 {
+			slices.Sort(removed)
+			fields[removeKey] = removed
+		}
+		out[name] = fields
+	}
+	f.mu.RUnlock()
+
+	data, err := json.MarshalIndent(out, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,134 @@
+package ecs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testLegacyFormat = LegacyFormat{
+	"stats": {Name: "Stats", Fields: []string{"Health", "Speed"}},
+	"bow":   {Name: "Bow", Fields: []string{"Range"}},
+	"Note":  {Fields: []string{"Text"}},
+}
+
+const testLegacyBlueprints = `goblin
+stats:30,1.5
+Note:sneaky
+
+archer
+stats:12
+bow:6
+`
+
+func TestImportLegacyBlueprints(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, nil)
+
+	names, err := f.ImportLegacyBlueprints(strings.NewReader(testLegacyBlueprints), testLegacyFormat)
+	assert.NoError(err)
+	assert.Equal([]string{"archer", "goblin"}, names)
+
+	bp, err := f.ResolvedBlueprint("goblin")
+	assert.NoError(err)
+	assert.Equal(BlueprintData{
+		"Stats": {"Health": 30.0, "Speed": 1.5},
+		"Note":  {"Text": "sneaky"},
+	}, bp, "Expected params typed by the registered component, strings otherwise")
+
+	e, err := f.Create("archer")
+	assert.NoError(err)
+	assert.Equal(12, e.GetComponent("Stats").(*statsComponent).Health)
+	assert.Equal(6, e.GetComponent("Bow").(*bowComponent).Range)
+}
+
+type doorComponent struct {
+	Locked bool
+	Width  int
+}
+
+func (c *doorComponent) GetType() ComponentType { return "Door" }
+
+func TestImportLegacyBlueprintsEmptyParams(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, nil)
+	f.RegisterComponent("Door", func() Component { return &doorComponent{} })
+	format := LegacyFormat{"door": {Name: "Door", Fields: []string{"Locked", "Width"}}}
+
+	_, err := f.ImportLegacyBlueprints(strings.NewReader("gate\ndoor:,\n\nhatch\ndoor:true,\n"), format)
+	assert.NoError(err)
+
+	gate, err := f.Create("gate")
+	assert.NoError(err)
+	assert.Equal(&doorComponent{}, gate.GetComponent("Door"), "Expected empty params to be zero values")
+	hatch, err := f.Create("hatch")
+	assert.NoError(err)
+	assert.Equal(&doorComponent{Locked: true}, hatch.GetComponent("Door"))
+}
+
+func TestImportLegacyBlueprintsErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"no descriptor": "goblin\narmor:3\n",
+		"no colon":      "goblin\nstats\n",
+		"too many":      "goblin\nbow:1,2\n",
+		"bad number":    "goblin\nstats:lots\n",
+	} {
+		f := newTestFactory(t, nil)
+		_, err := f.ImportLegacyBlueprints(strings.NewReader(content), testLegacyFormat)
+		assert.Error(t, err, name)
+		assert.False(t, f.BlueprintExists("goblin"), "Expected a failed import to add nothing: %s", name)
+	}
+}
+
+func TestExportLegacyBlueprintsRoundTrips(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, map[string]string{
+		"base.json": `{
+			"goblin": {"Stats": {"Health": 30, "Speed": 1.5}},
+			"goblin_archer": {"extends": "goblin", "Bow": {"Range": 6}}
+		}`,
+	})
+
+	var buf bytes.Buffer
+	assert.NoError(f.ExportLegacyBlueprints(&buf, testLegacyFormat))
+	assert.Equal("goblin\nstats:30,1.5\n\ngoblin_archer\nbow:6\nstats:30,1.5\n", buf.String())
+
+	g := newTestFactory(t, nil)
+	_, err := g.ImportLegacyBlueprints(&buf, testLegacyFormat)
+	assert.NoError(err)
+	want, _ := f.ResolvedBlueprint("goblin_archer")
+	got, _ := g.ResolvedBlueprint("goblin_archer")
+	assert.Equal(want, got)
+
+	f = newTestFactory(t, map[string]string{"x.json": `{"knight": {"Armor": {"Value": 2}}}`})
+	assert.Error(f.ExportLegacyBlueprints(&buf, testLegacyFormat), "Expected undescribed components to fail")
+}
+
+func TestWriteBlueprintsMigratesLegacyContent(t *testing.T) {
+	assert := assert.New(t)
+	f, dir := newTestFactoryDir(t, map[string]string{
+		"base.json": `{"goblin_chief": {"extends": "goblin", "Bow": {"Range": 9}}}`,
+	})
+	_, err := f.ImportLegacyBlueprints(strings.NewReader(testLegacyBlueprints), testLegacyFormat)
+	assert.NoError(err)
+
+	changed, err := f.Reload(nil)
+	assert.NoError(err)
+	assert.Empty(changed)
+	assert.True(f.BlueprintExists("goblin"), "Expected Reload to keep imported blueprints")
+
+	var buf bytes.Buffer
+	assert.NoError(f.WriteBlueprints(&buf))
+	path := writeBlueprints(t, dir, "migrated.json", buf.String())
+
+	g := newTestFactory(t, nil)
+	assert.NoError(g.LoadBlueprintsFromFile(path))
+	for _, name := range []string{"goblin", "archer", "goblin_chief"} {
+		want, _ := f.ResolvedBlueprint(name)
+		got, err := g.ResolvedBlueprint(name)
+		assert.NoError(err, name)
+		assert.Equal(want, got, name)
+	}
+}
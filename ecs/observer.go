@@ -0,0 +1,162 @@
+package ecs
+
+import "github.com/mechanical-lich/mlge/event"
+
+// ComponentObserver is called when a component of the observed type is added
+// to, removed from or marked changed on an entity owned by a World. c is the
+// component involved: the new value for added/changed, the old value for removed.
+//
+// Observers run synchronously on the goroutine that made the change. While
+// the world defers component changes (see World.DeferComponentChanges), as it
+// does during parallel simulation stages, notifications are held back and
+// delivered on the deferring goroutine once deferral ends, so observers are
+// never called concurrently. They must not spawn or despawn entities
+// directly; record structural changes in the world's CommandBuffer instead.
+type ComponentObserver func(e *Entity, c Component)
+
+// ObserverHandle identifies a registration so it can be removed with
+// World.RemoveObserver.
+type ObserverHandle uint64
+
+const (
+	EventTypeComponentAdded   event.EventType = "ecs_component_added"
+	EventTypeComponentRemoved event.EventType = "ecs_component_removed"
+	EventTypeComponentChanged event.EventType = "ecs_component_changed"
+)
+
+// ComponentEvent is the event.EventData sent by ForwardComponentEvents.
+type ComponentEvent struct {
+	Kind          event.EventType
+	Entity        *Entity
+	ComponentType ComponentType
+	Component     Component
+}
+
+func (e ComponentEvent) GetType() event.EventType {
+	return e.Kind
+}
+
+type observerEntry struct {
+	handle ObserverHandle
+	fn     ComponentObserver
+}
+
+type componentObservers struct {
+	added, removed, changed []observerEntry
+}
+
+// OnAdded registers fn to run whenever an entity in w gains a component of
+// type t, including when an entity that already has one is added to w.
+func (w *World) OnAdded(t ComponentType, fn ComponentObserver) ObserverHandle {
+	h := w.nextObserverHandle()
+	w.observersFor(t).added = append(w.observersFor(t).added, observerEntry{h, fn})
+	return h
+}
+
+// OnRemoved registers fn to run whenever an entity in w loses a component of
+// type t, including when the entity is despawned.
+func (w *World) OnRemoved(t ComponentType, fn ComponentObserver) ObserverHandle {
+	h := w.nextObserverHandle()
+	w.observersFor(t).removed = append(w.observersFor(t).removed, observerEntry{h, fn})
+	return h
+}
+
+// OnChanged registers fn to run whenever a component of type t on an entity
+// in w is replaced through AddComponent or flagged with Entity.MarkChanged.
+func (w *World) OnChanged(t ComponentType, fn ComponentObserver) ObserverHandle {
+	h := w.nextObserverHandle()
+	w.observersFor(t).changed = append(w.observersFor(t).changed, observerEntry{h, fn})
+	return h
+}
+
+// ForwardComponentEvents delivers add/remove/change notifications for t as
+// ComponentEvent values through send, e.g. an event.EventManager's SendEvent
+// or an event.QueuedEventManager's QueueEvent. The returned handle removes
+// all three registrations.
+func (w *World) ForwardComponentEvents(t ComponentType, send func(event.EventData)) ObserverHandle {
+	h := w.nextObserverHandle()
+	forward := func(kind event.EventType) ComponentObserver {
+		return func(e *Entity, c Component) {
+			send(ComponentEvent{Kind: kind, Entity: e, ComponentType: t, Component: c})
+		}
+	}
+	obs := w.observersFor(t)
+	obs.added = append(obs.added, observerEntry{h, forward(EventTypeComponentAdded)})
+	obs.removed = append(obs.removed, observerEntry{h, forward(EventTypeComponentRemoved)})
+	obs.changed = append(obs.changed, observerEntry{h, forward(EventTypeComponentChanged)})
+	return h
+}
+
+// RemoveObserver unregisters every observer registered under h.
+func (w *World) RemoveObserver(h ObserverHandle) {
+	for _, obs := range w.observers {
+		obs.added = removeObserverEntries(obs.added, h)
+		obs.removed = removeObserverEntries(obs.removed, h)
+		obs.changed = removeObserverEntries(obs.changed, h)
+	}
+}
+
+// MarkChanged - Notifies the owning World's OnChanged observers that a component was modified in place (e.g.
+// through a pointer). Does nothing if the entity has no such component or is not in a World.
+func (entity *Entity) MarkChanged(t ComponentType) {
+	if entity.world == nil {
+		return
+	}
+	if entity.world.deferring != deferNone {
+		entity.world.deferred.changed(entity, t)
+		return
+	}
+	if c, ok := entity.Components[t]; ok {
+		entity.world.notifyChanged(entity, t, c)
+	}
+}
+
+func (w *World) observersFor(t ComponentType) *componentObservers {
+	if w.observers == nil {
+		w.observers = make(map[ComponentType]*componentObservers)
+	}
+	obs := w.observers[t]
+	if obs == nil {
+		obs = &componentObservers{}
+		w.observers[t] = obs
+	}
+	return obs
+}
+
+func (w *World) nextObserverHandle() ObserverHandle {
+	w.lastObserver++
+	return w.lastObserver
+}
+
+func (w *World) notify(t ComponentType, c Component, e *Entity, list func(*componentObservers) []observerEntry) {
+	obs := w.observers[t]
+	if obs == nil {
+		return
+	}
+	// Observers may register or remove observers; iterate over a snapshot.
+	for _, entry := range append([]observerEntry(nil), list(obs)...) {
+		entry.fn(e, c)
+	}
+}
+
+func (w *World) notifyAdded(e *Entity, t ComponentType, c Component) {
+	w.notify(t, c, e, func(o *componentObservers) []observerEntry { return o.added })
+}
+
+func (w *World) notifyRemoved(e *Entity, t ComponentType, c Component) {
+	w.notify(t, c, e, func(o *componentObservers) []observerEntry { return o.removed })
+}
+
+func (w *World) notifyChanged(e *Entity, t ComponentType, c Component) {
+	w.notify(t, c, e, func(o *componentObservers) []observerEntry { return o.changed })
+}
+
+func removeObserverEntries(entries []observerEntry, h ObserverHandle) []observerEntry {
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.handle != h {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
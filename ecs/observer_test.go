@@ -0,0 +1,116 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/mechanical-lich/mlge/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type observedValue struct{ N int }
+
+func (observedValue) GetType() ComponentType { return "ObservedValue" }
+
+func TestObserversFireOnComponentChanges(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	var log []string
+	w.OnAdded("ObservedValue", func(e *Entity, c Component) { log = append(log, "added") })
+	w.OnChanged("ObservedValue", func(e *Entity, c Component) { log = append(log, "changed") })
+	w.OnRemoved("ObservedValue", func(e *Entity, c Component) { log = append(log, "removed") })
+
+	e := w.Spawn()
+	e.AddComponent(observedValue{N: 1})
+	Mutate(e, func(v *observedValue) { v.N++ })
+	e.MarkChanged("ObservedValue")
+	e.MarkChanged(testComponentType)
+	e.RemoveComponent("ObservedValue")
+	e.RemoveComponent("ObservedValue")
+
+	assert.Equal([]string{"added", "changed", "changed", "removed"}, log)
+}
+
+func TestObserversFireOnSpawnAndDespawn(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	var added, removed int
+	w.OnAdded(testComponentType, func(e *Entity, c Component) { added++ })
+	h := w.OnRemoved(testComponentType, func(e *Entity, c Component) {
+		assert.True(w.Alive(e.ID), "Expected the entity to still be alive while observers run")
+		removed++
+	})
+
+	e := &Entity{}
+	e.AddComponent(TestComponent{})
+	assert.Equal(0, added, "Expected no notification for entities outside a world")
+	w.Add(e)
+	w.Despawn(e.ID)
+
+	assert.Equal(1, added)
+	assert.Equal(1, removed)
+
+	w.RemoveObserver(h)
+	w.Despawn(w.Spawn().ID)
+	other := w.Spawn()
+	other.AddComponent(TestComponent{})
+	w.Despawn(other.ID)
+	assert.Equal(1, removed, "Expected removed observer not to fire")
+}
+
+func TestForwardComponentEvents(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	mgr := &event.QueuedEventManager{}
+	var kinds []event.EventType
+	h := w.ForwardComponentEvents("ObservedValue", mgr.QueueEvent)
+
+	e := w.Spawn()
+	e.AddComponent(observedValue{N: 1})
+	e.MarkChanged("ObservedValue")
+	w.Despawn(e.ID)
+	w.RemoveObserver(h)
+	w.Spawn().AddComponent(observedValue{})
+
+	for _, kind := range []event.EventType{EventTypeComponentAdded, EventTypeComponentChanged, EventTypeComponentRemoved} {
+		mgr.RegisterListener(listenerFunc(func(data event.EventData) error {
+			ev := data.(ComponentEvent)
+			assert.Equal(e, ev.Entity)
+			assert.Equal(observedValue{N: 1}, ev.Component)
+			kinds = append(kinds, ev.GetType())
+			return nil
+		}), kind)
+	}
+	mgr.HandleQueue()
+
+	assert.Equal([]event.EventType{EventTypeComponentAdded, EventTypeComponentChanged, EventTypeComponentRemoved}, kinds)
+}
+
+type listenerFunc func(event.EventData) error
+
+func (f listenerFunc) HandleEvent(data event.EventData) error { return f(data) }
+
+func TestObserversWaitForDeferredChanges(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	e := w.Spawn()
+	e.AddComponent(observedValue{N: 1})
+	var seen []int
+	w.OnChanged("ObservedValue", func(e *Entity, c Component) { seen = append(seen, c.(observedValue).N) })
+
+	apply := w.DeferComponentChanges()
+	e.AddComponent(observedValue{N: 2})
+	e.MarkChanged("ObservedValue")
+	assert.Equal(observedValue{N: 2}, Get[observedValue](e), "Expected replacements to take effect at once")
+	assert.Empty(seen, "Expected notifications to wait for apply")
+	assert.Nil(apply())
+	assert.Equal([]int{2, 2}, seen)
+
+	seen = nil
+	apply = w.DeferAllComponentChanges()
+	e.AddComponent(observedValue{N: 3})
+	e.MarkChanged("ObservedValue")
+	assert.Equal(observedValue{N: 2}, Get[observedValue](e), "Expected replacements to wait for apply")
+	assert.Nil(apply())
+	assert.Equal(observedValue{N: 3}, Get[observedValue](e))
+	assert.Equal([]int{3, 3}, seen, "Expected notifications to carry the applied component")
+}
@@ -0,0 +1,221 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// componentPrototype is one decoded component of a blueprint, cached so
+// pooled spawns copy it instead of decoding JSON again.
+type componentPrototype struct {
+	name   string
+	value  Component              // decoded component; nil when it must be decoded per spawn
+	copy   bool                   // value is a pointer whose target is copied into each clone
+	params map[string]interface{} // resolved parameters, used when value is nil
+}
+
+// prototype returns the cached decoded components of the named blueprint,
+// building them on first use. The cache is dropped whenever blueprints load.
+func (f *JSONFactory) prototype(name string) ([]componentPrototype, error) {
+	f.mu.RLock()
+	protos, ok := f.prototypes[name]
+	f.mu.RUnlock()
+	if ok {
+		return protos, nil
+	}
+
+	blueprint, err := f.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	protos = make([]componentPrototype, 0, len(blueprint))
+	for compName, params := range blueprint {
+		comp, err := f.CreateComponent(compName, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create component %s for %s: %w", compName, name, err)
+		}
+		proto := componentPrototype{name: compName}
+		switch t := reflect.TypeOf(comp); {
+		case t.Kind() == reflect.Pointer && flatType(t.Elem()):
+			proto.value, proto.copy = comp, true
+		case flatType(t):
+			proto.value = comp // held by value, so sharing it is safe
+		default:
+			proto.params = params
+		}
+		protos = append(protos, proto)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, current := f.resolved[name]; current {
+		f.prototypes[name] = protos
+	}
+	return protos, nil
+}
+
+// invalidateLocked drops the resolved blueprints and prototypes after the
+// blueprint set changes. f.mu must be held.
+func (f *JSONFactory) invalidateLocked() {
+	f.resolved = make(map[string]BlueprintData)
+	f.prototypes = make(map[string][]componentPrototype)
+}
+
+// flatType reports whether values of t hold no references, so a plain copy
+// is a deep copy.
+func flatType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return flatType(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !flatType(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// EntityPool is a spawn path for entities created and despawned at a high
+// rate, such as projectiles. It creates entities from a JSONFactory's
+// blueprints like Create does, but copies cached, already decoded components
+// instead of decoding JSON on every spawn, and reuses the Entity, its
+// component map and its component values once they are released.
+//
+// Components whose structs hold only value fields (numbers, strings, bools,
+// arrays and structs of those) are copied and recycled. Components with
+// slices, maps or pointers are decoded from JSON each spawn, as Create does.
+//
+// Attach the pool to a World with [World.SetEntityPool] so despawned
+// entities are released to it automatically. A released entity and its
+// components are reused by later spawns: do not keep *Entity or component
+// pointers past Despawn; keep the EntityID, which stays safely dead.
+//
+// An EntityPool is not safe for concurrent use. Create with [NewEntityPool].
+type EntityPool struct {
+	Factory *JSONFactory
+
+	entities   []*Entity
+	components map[reflect.Type][]Component
+}
+
+// NewEntityPool creates an empty pool spawning from f's blueprints.
+func NewEntityPool(f *JSONFactory) *EntityPool {
+	return &EntityPool{
+		Factory:    f,
+		components: make(map[reflect.Type][]Component),
+	}
+}
+
+// Create returns an entity built from the named blueprint, reusing a
+// released entity and released components when available.
+func (p *EntityPool) Create(name string) (*Entity, error) {
+	protos, err := p.Factory.prototype(name)
+	if err != nil {
+		return nil, err
+	}
+	var entity *Entity
+	if n := len(p.entities); n > 0 {
+		entity = p.entities[n-1]
+		p.entities[n-1] = nil
+		p.entities = p.entities[:n-1]
+		// Drop components added after release, e.g. by a buffered command
+		// recorded before the entity was despawned.
+		clear(entity.Components)
+	} else {
+		entity = &Entity{pool: p}
+	}
+	entity.Blueprint = name
+
+	for _, proto := range protos {
+		comp, err := p.clone(proto)
+		if err != nil {
+			p.Release(entity)
+			return nil, fmt.Errorf("failed to create component %s for %s: %w", proto.name, name, err)
+		}
+		entity.AddComponent(comp)
+	}
+	return entity, nil
+}
+
+// Spawn creates an entity from the named blueprint and adds it to w.
+func (p *EntityPool) Spawn(w *World, name string) (*Entity, error) {
+	entity, err := p.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Add(entity); err != nil {
+		p.Release(entity)
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Release returns an entity and its components to the pool for reuse.
+// Entities still in a World are ignored; despawn them instead. A World with
+// this pool attached releases despawned entities itself.
+func (p *EntityPool) Release(entity *Entity) {
+	if entity.world != nil {
+		return
+	}
+	for _, comp := range entity.Components {
+		t := reflect.TypeOf(comp)
+		if t.Kind() == reflect.Pointer && p.recyclable(t) {
+			p.components[t] = append(p.components[t], comp)
+		}
+	}
+	clear(entity.Components)
+	*entity = Entity{Components: entity.Components, pool: p}
+	p.entities = append(p.entities, entity)
+}
+
+// Free returns the number of released entities waiting for reuse.
+func (p *EntityPool) Free() int {
+	return len(p.entities)
+}
+
+// recyclable reports whether released values of pointer type t may be reused.
+func (p *EntityPool) recyclable(t reflect.Type) bool {
+	if _, ok := p.components[t]; ok {
+		return true
+	}
+	if !flatType(t.Elem()) {
+		return false
+	}
+	p.components[t] = nil
+	return true
+}
+
+func (p *EntityPool) clone(proto componentPrototype) (Component, error) {
+	if proto.value == nil {
+		return p.Factory.CreateComponent(proto.name, proto.params)
+	}
+	if !proto.copy {
+		return proto.value, nil
+	}
+	t := reflect.TypeOf(proto.value)
+	var comp Component
+	if free := p.components[t]; len(free) > 0 {
+		comp = free[len(free)-1]
+		free[len(free)-1] = nil
+		p.components[t] = free[:len(free)-1]
+	} else {
+		comp = reflect.New(t.Elem()).Interface().(Component)
+	}
+	reflect.ValueOf(comp).Elem().Set(reflect.ValueOf(proto.value).Elem())
+	return comp, nil
+}
+
+// SetEntityPool makes Despawn release despawned entities to p for reuse.
+// Only entities p created are released; others are left to the garbage
+// collector. Pass nil to stop recycling.
+func (w *World) SetEntityPool(p *EntityPool) {
+	w.pool = p
+}
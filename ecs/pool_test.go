@@ -0,0 +1,165 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPoolBlueprints = `{
+	"arrow": {"Bow": {"Range": 7}, "Armor": {"Value": 1}},
+	"goblin": {"Stats": {"Health": 30, "Tags": ["green"]}}
+}`
+
+func TestEntityPoolCopiesPrototypes(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, map[string]string{"bp.json": testPoolBlueprints})
+	pool := NewEntityPool(f)
+
+	a, err := pool.Create("arrow")
+	assert.NoError(err)
+	assert.Equal("arrow", a.Blueprint)
+	a.GetComponent("Bow").(*bowComponent).Range = 99
+
+	b, err := pool.Create("arrow")
+	assert.NoError(err)
+	assert.Equal(7, b.GetComponent("Bow").(*bowComponent).Range, "Expected spawns not to share component values")
+
+	g1, _ := pool.Create("goblin")
+	g2, _ := pool.Create("goblin")
+	g1.GetComponent("Stats").(*statsComponent).Tags[0] = "red"
+	assert.Equal("green", g2.GetComponent("Stats").(*statsComponent).Tags[0], "Expected components with slices to be decoded per spawn")
+
+	_, err = pool.Create("missing")
+	assert.Error(err)
+}
+
+func TestEntityPoolRecyclesDespawnedEntities(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, map[string]string{"bp.json": testPoolBlueprints})
+	pool := NewEntityPool(f)
+	w := NewWorld()
+	w.SetEntityPool(pool)
+
+	e, err := pool.Spawn(w, "arrow")
+	assert.NoError(err)
+	e.SetTag(testHostile)
+	bow := e.GetComponent("Bow").(*bowComponent)
+	bow.Range = 1
+	id := e.ID
+
+	w.Despawn(id)
+	assert.Equal(1, pool.Free())
+	assert.Empty(e.Components)
+	assert.False(e.HasTag(testHostile))
+
+	again, err := pool.Spawn(w, "arrow")
+	assert.NoError(err)
+	assert.Same(e, again, "Expected the released entity to be reused")
+	assert.Same(bow, again.GetComponent("Bow"), "Expected the released component to be reused")
+	assert.Equal(7, bow.Range)
+	assert.NotEqual(id, again.ID)
+	assert.Nil(w.Get(id))
+}
+
+func TestEntityPoolPicksUpReloadedBlueprints(t *testing.T) {
+	assert := assert.New(t)
+	f, dir := newTestFactoryDir(t, map[string]string{"bp.json": testPoolBlueprints})
+	pool := NewEntityPool(f)
+	_, err := pool.Create("arrow")
+	assert.NoError(err)
+
+	writeBlueprints(t, dir, "bp.json", `{"arrow": {"Bow": {"Range": 12}}}`)
+	_, err = f.Reload(nil)
+	assert.NoError(err)
+
+	e, err := pool.Create("arrow")
+	assert.NoError(err)
+	assert.Equal(12, e.GetComponent("Bow").(*bowComponent).Range)
+	assert.False(e.HasComponent("Armor"))
+}
+
+func TestPooledSpawnDoesNotAllocate(t *testing.T) {
+	f := newTestFactory(t, map[string]string{"bp.json": testPoolBlueprints})
+	pool := NewEntityPool(f)
+	w := NewWorld()
+	w.SetEntityPool(pool)
+	w.Query("Bow")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		e, _ := pool.Spawn(w, "arrow")
+		w.Despawn(e.ID)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkSpawnDespawnFactory(b *testing.B) {
+	f := newTestFactory(b, map[string]string{"bp.json": testPoolBlueprints})
+	w := NewWorld()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e, _ := f.Create("arrow")
+		w.Add(e)
+		w.Despawn(e.ID)
+	}
+}
+
+func BenchmarkSpawnDespawnPool(b *testing.B) {
+	f := newTestFactory(b, map[string]string{"bp.json": testPoolBlueprints})
+	pool := NewEntityPool(f)
+	w := NewWorld()
+	w.SetEntityPool(pool)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e, _ := pool.Spawn(w, "arrow")
+		w.Despawn(e.ID)
+	}
+}
+
+func TestEntityPoolDropsCommandsForReleasedEntities(t *testing.T) {
+	assert := assert.New(t)
+	f := newTestFactory(t, map[string]string{"bp.json": testPoolBlueprints})
+	pool := NewEntityPool(f)
+	w := NewWorld()
+	w.SetEntityPool(pool)
+
+	e, err := pool.Spawn(w, "arrow")
+	assert.NoError(err)
+	w.Commands().Despawn(e.ID)
+	w.Commands().AddComponent(e, TestComponent{})
+	w.Commands().RemoveComponent(e, "Bow")
+	assert.NoError(w.FlushCommands())
+	assert.Empty(e.Components, "Expected commands on a despawned entity to be skipped")
+
+	e.AddComponent(TestComponent{}) // a stale direct write after release
+	again, err := pool.Spawn(w, "arrow")
+	assert.NoError(err)
+	assert.Same(e, again)
+	assert.False(again.HasComponent(testComponentType), "Expected no stale components on reuse")
+
+	// A command recorded before the entity was recycled must not reach it.
+	w.Commands().AddComponent(again, TestComponent2{})
+	w.Despawn(again.ID)
+	_, err = pool.Spawn(w, "arrow")
+	assert.NoError(err)
+	assert.NoError(w.FlushCommands())
+	assert.False(again.HasComponent(testComponent2Type))
+}
+
+func TestWorldReleasesOnlyPooledEntities(t *testing.T) {
+	f := newTestFactory(t, map[string]string{"bp.json": testPoolBlueprints})
+	pool := NewEntityPool(f)
+	w := NewWorld()
+	w.SetEntityPool(pool)
+
+	foreign := w.Spawn()
+	foreign.AddComponent(TestComponent{})
+	w.Despawn(foreign.ID)
+	assert.Equal(t, 0, pool.Free(), "Expected entities the pool did not create to be left alone")
+
+	other := NewEntityPool(f)
+	e, err := other.Spawn(w, "arrow")
+	assert.NoError(t, err)
+	w.Despawn(e.ID)
+	assert.Equal(t, 0, pool.Free())
+}
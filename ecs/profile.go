@@ -0,0 +1,272 @@
+package ecs
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Pass identifies which half of a system's work a timing sample covers.
+type Pass int
+
+const (
+	GlobalPass Pass = iota // UpdateSystem / UpdateSimulation / UpdateRender
+	EntityPass             // the per-entity calls of one update
+)
+
+// DefaultProfileWindow is the number of samples a Profiler keeps per series
+// when created with a window of 0.
+const DefaultProfileWindow = 120
+
+// Profiler records how long each system of a manager takes, for the global
+// and per-entity passes separately, along with how many entities each
+// per-entity pass processed. It keeps the most recent samples of every
+// series, up to the window given to NewProfiler, and reports rolling averages
+// and percentiles over them.
+//
+// Profiling is opt-in: attach a Profiler to ecs.SystemManager,
+// simulation.SimulationSystemManager or client.RenderSystemManager with
+// SetProfiler. A manager without one does no timing at all. A Profiler is
+// safe for concurrent use but should be attached to only one manager, since
+// systems are identified by their registration index.
+//
+// Create with [NewProfiler].
+type Profiler struct {
+	// Budget is how long a whole tick may take. Ticks reported through
+	// RecordTick that take longer are counted as overruns. Zero disables the
+	// check.
+	Budget time.Duration
+
+	mu       sync.Mutex
+	window   int
+	systems  []*systemSeries
+	ticks    sampleRing
+	overruns int
+}
+
+type systemSeries struct {
+	name     string
+	global   sampleRing
+	entity   sampleRing
+	entities []int // entity counts, parallel to entity samples
+	next     int
+}
+
+// NewProfiler creates a Profiler that keeps window samples per series.
+// A window of 0 or less uses DefaultProfileWindow.
+func NewProfiler(window int) *Profiler {
+	if window <= 0 {
+		window = DefaultProfileWindow
+	}
+	return &Profiler{window: window}
+}
+
+// Start returns the time a pass starts, or the zero time if p is nil, so
+// managers can bracket a pass without checking for a profiler first.
+func (p *Profiler) Start() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// Record adds one timing sample for the system registered at index under name.
+// entities is the number of entities the pass processed and is ignored for
+// GlobalPass. System managers call this; games only need it for custom loops.
+func (p *Profiler) Record(index int, name string, pass Pass, d time.Duration, entities int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.systems) <= index {
+		p.systems = append(p.systems, nil)
+	}
+	s := p.systems[index]
+	if s == nil {
+		s = &systemSeries{name: name, entities: make([]int, 0, p.window)}
+		p.systems[index] = s
+	}
+	if pass == GlobalPass {
+		s.global.add(d, p.window)
+		return
+	}
+	s.entity.add(d, p.window)
+	if len(s.entities) < p.window {
+		s.entities = append(s.entities, entities)
+	} else {
+		s.entities[s.next] = entities
+		s.next = (s.next + 1) % p.window
+	}
+}
+
+// RecordTick adds the wall time of one whole tick or frame and counts it as
+// an overrun if it exceeds Budget. simulation.Server and client.Client call
+// this for their own loops.
+func (p *Profiler) RecordTick(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ticks.add(d, p.window)
+	if p.Budget > 0 && d > p.Budget {
+		p.overruns++
+	}
+}
+
+// Reset discards every sample and the overrun count.
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.systems = nil
+	p.ticks = sampleRing{}
+	p.overruns = 0
+}
+
+// TimingStats summarises the samples of one series.
+type TimingStats struct {
+	Samples       int
+	Last          time.Duration
+	Avg           time.Duration
+	P50, P95, P99 time.Duration
+	Max           time.Duration
+}
+
+// SystemProfile is the profile of one system in a ProfileReport.
+type SystemProfile struct {
+	Name         string
+	Global       TimingStats
+	Entity       TimingStats
+	LastEntities int     // entities processed by the latest per-entity pass
+	AvgEntities  float64 // mean entities per per-entity pass
+}
+
+// Total returns the system's mean time per update across both passes.
+func (s SystemProfile) Total() time.Duration {
+	return s.Global.Avg + s.Entity.Avg
+}
+
+// ProfileReport is a snapshot of a Profiler's statistics.
+type ProfileReport struct {
+	Systems  []SystemProfile // in registration order; systems never run are omitted
+	Tick     TimingStats     // from RecordTick
+	Budget   time.Duration
+	Overruns int // ticks over Budget since the last Reset
+}
+
+// Slowest returns the systems sorted by Total, slowest first.
+func (r ProfileReport) Slowest() []SystemProfile {
+	out := slices.Clone(r.Systems)
+	slices.SortStableFunc(out, func(a, b SystemProfile) int {
+		return cmp.Compare(b.Total(), a.Total())
+	})
+	return out
+}
+
+// String formats the report as a table, slowest systems first.
+func (r ProfileReport) String() string {
+	return r.Format(0)
+}
+
+// Format formats the report as a table of at most maxSystems systems,
+// slowest first; 0 lists them all. System times are averages over the window
+// except the per-entity pass p95.
+func (r ProfileReport) Format(maxSystems int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tick avg %v p95 %v max %v", r.Tick.Avg, r.Tick.P95, r.Tick.Max)
+	if r.Budget > 0 {
+		fmt.Fprintf(&b, " budget %v overruns %d", r.Budget, r.Overruns)
+	}
+	b.WriteByte('\n')
+	fmt.Fprintf(&b, "%-24s %10s %10s %10s %8s\n", "system", "global", "entity", "entity p95", "entities")
+	systems := r.Slowest()
+	if maxSystems > 0 && len(systems) > maxSystems {
+		systems = systems[:maxSystems]
+	}
+	for _, s := range systems {
+		name := s.Name
+		if len(name) > 24 {
+			name = name[:23] + "~"
+		}
+		fmt.Fprintf(&b, "%-24s %10v %10v %10v %8.0f\n",
+			name, s.Global.Avg, s.Entity.Avg, s.Entity.P95, s.AvgEntities)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Report returns the current statistics.
+func (p *Profiler) Report() ProfileReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := ProfileReport{
+		Tick:     p.ticks.stats(),
+		Budget:   p.Budget,
+		Overruns: p.overruns,
+	}
+	for _, s := range p.systems {
+		if s == nil {
+			continue
+		}
+		sp := SystemProfile{Name: s.name, Global: s.global.stats(), Entity: s.entity.stats()}
+		if n := len(s.entities); n > 0 {
+			sp.LastEntities = s.entities[(s.next+n-1)%n]
+			total := 0
+			for _, c := range s.entities {
+				total += c
+			}
+			sp.AvgEntities = float64(total) / float64(n)
+		}
+		r.Systems = append(r.Systems, sp)
+	}
+	return r
+}
+
+// sampleRing keeps the most recent samples of one series.
+type sampleRing struct {
+	samples []time.Duration
+	next    int // slot overwritten by the next sample once full
+}
+
+func (r *sampleRing) add(d time.Duration, window int) {
+	if len(r.samples) < window {
+		r.samples = append(r.samples, d)
+		return
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % window
+}
+
+func (r *sampleRing) stats() TimingStats {
+	n := len(r.samples)
+	if n == 0 {
+		return TimingStats{}
+	}
+	sorted := slices.Clone(r.samples)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return TimingStats{
+		Samples: n,
+		Last:    r.samples[(r.next+n-1)%n],
+		Avg:     total / time.Duration(n),
+		P50:     percentile(sorted, 50),
+		P95:     percentile(sorted, 95),
+		P99:     percentile(sorted, 99),
+		Max:     sorted[n-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []time.Duration, pct int) time.Duration {
+	rank := (pct*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// SystemName returns the name a profiler reports for a system: its
+// SystemOptions name if it has one, otherwise its Go type.
+func SystemName(system any, opts SystemOptions) string {
+	if opts.Name != "" {
+		return opts.Name
+	}
+	return fmt.Sprintf("%T", system)
+}
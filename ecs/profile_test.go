@@ -0,0 +1,82 @@
+package ecs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfilerStats(t *testing.T) {
+	assert := assert.New(t)
+	p := NewProfiler(4)
+
+	for _, ms := range []int{9, 1, 2, 3, 4} { // 9 falls out of the window
+		p.Record(0, "move", EntityPass, time.Duration(ms)*time.Millisecond, ms*10)
+	}
+	p.Record(1, "ai", GlobalPass, 5*time.Millisecond, 0)
+
+	r := p.Report()
+	assert.Len(r.Systems, 2)
+	move := r.Systems[0]
+	assert.Equal("move", move.Name)
+	assert.Equal(4, move.Entity.Samples)
+	assert.Equal(4*time.Millisecond, move.Entity.Last)
+	assert.Equal(2500*time.Microsecond, move.Entity.Avg)
+	assert.Equal(2*time.Millisecond, move.Entity.P50)
+	assert.Equal(4*time.Millisecond, move.Entity.P95)
+	assert.Equal(4*time.Millisecond, move.Entity.Max)
+	assert.Equal(40, move.LastEntities)
+	assert.Equal(25.0, move.AvgEntities)
+	assert.Equal(0, move.Global.Samples)
+
+	assert.Equal([]string{"ai", "move"}, []string{r.Slowest()[0].Name, r.Slowest()[1].Name})
+}
+
+func TestProfilerTickBudget(t *testing.T) {
+	assert := assert.New(t)
+	p := NewProfiler(0)
+	p.Budget = 50 * time.Millisecond
+
+	p.RecordTick(40 * time.Millisecond)
+	p.RecordTick(60 * time.Millisecond)
+	p.RecordTick(70 * time.Millisecond)
+
+	r := p.Report()
+	assert.Equal(2, r.Overruns)
+	assert.Equal(70*time.Millisecond, r.Tick.Max)
+	assert.True(strings.Contains(r.String(), "overruns 2"))
+
+	p.Reset()
+	assert.Equal(0, p.Report().Overruns)
+	assert.Equal(0, p.Report().Tick.Samples)
+}
+
+func TestSystemManagerProfiling(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	for i := 0; i < 3; i++ {
+		w.Spawn().AddComponent(TestComponent{})
+	}
+	w.Spawn()
+
+	sm := &SystemManager{}
+	sm.AddSystemWithOptions(&MockSystem{RequiredComponents: []ComponentType{testComponentType}}, SystemOptions{Name: "mock"})
+	sm.AddSystem(&MockSystem{})
+	p := NewProfiler(0)
+	sm.SetProfiler(p)
+
+	assert.Nil(sm.UpdateSystems(w))
+	assert.Nil(sm.UpdateSystemsForWorld(w, w))
+	assert.Nil(sm.UpdateSystemsForEntities(w, w.Entities()))
+
+	r := p.Report()
+	assert.Len(r.Systems, 2)
+	assert.Equal("mock", r.Systems[0].Name)
+	assert.Equal("*ecs.MockSystem", r.Systems[1].Name, "Expected unnamed systems to be labelled by type")
+	assert.Equal(1, r.Systems[0].Global.Samples)
+	assert.Equal(2, r.Systems[0].Entity.Samples)
+	assert.Equal(3, r.Systems[0].LastEntities)
+	assert.Equal(4, r.Systems[1].LastEntities)
+}
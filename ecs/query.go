@@ -0,0 +1,168 @@
+package ecs
+
+import (
+	"slices"
+	"strings"
+)
+
+// Query is a cached set of the live entities in a [World] that have all of a
+// list of component types.
+//
+// The set is maintained incrementally: entities are added or removed as they
+// are spawned, despawned, or gain and lose components through
+// [Entity.AddComponent] and [Entity.RemoveComponent]. Writing to
+// Entity.Components directly bypasses this bookkeeping.
+//
+// Obtain a Query with [World.Query]; queries are shared, so asking twice for
+// the same component set returns the same Query.
+type Query struct {
+	required []ComponentType
+	entities []*Entity
+	index    map[EntityID]int
+	scratch  []*Entity // reused by Each
+}
+
+// Entities returns the matching entities. The slice is owned by the query and
+// is only valid until the next structural change; do not modify it.
+// Use a CommandBuffer for structural changes made while iterating.
+func (q *Query) Entities() []*Entity {
+	return q.entities
+}
+
+// Each calls f for every matching entity, stopping at the first error.
+//
+// Unlike ranging over Entities, f may make structural changes directly, such
+// as removing one of the query's components or despawning the entity: Each
+// walks the entities that matched when it started and skips any that stop
+// matching before they are reached. Entities that start matching during the
+// walk are not visited until the next one.
+func (q *Query) Each(f func(e *Entity) error) error {
+	walk := append(q.scratch[:0], q.entities...)
+	q.scratch = nil // a nested walk gets its own buffer
+	defer func() {
+		clear(walk)
+		q.scratch = walk
+	}()
+	for _, e := range walk {
+		if !q.contains(e) {
+			continue
+		}
+		if err := f(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contains reports whether e itself, not just an entity with its ID, is in
+// the query.
+func (q *Query) contains(e *Entity) bool {
+	pos, ok := q.index[e.ID]
+	return ok && q.entities[pos] == e
+}
+
+// Len returns the number of matching entities.
+func (q *Query) Len() int {
+	return len(q.entities)
+}
+
+// Requires returns the component types the query matches on.
+func (q *Query) Requires() []ComponentType {
+	return q.required
+}
+
+func (q *Query) add(e *Entity) {
+	if _, ok := q.index[e.ID]; ok {
+		return
+	}
+	q.index[e.ID] = len(q.entities)
+	q.entities = append(q.entities, e)
+}
+
+func (q *Query) remove(e *Entity) {
+	pos, ok := q.index[e.ID]
+	if !ok {
+		return
+	}
+	last := len(q.entities) - 1
+	moved := q.entities[last]
+	q.entities[pos] = moved
+	q.index[moved.ID] = pos
+	q.entities[last] = nil
+	q.entities = q.entities[:last]
+	delete(q.index, e.ID)
+}
+
+// Query returns the cached query for entities having all of the given
+// component types, creating and populating it on first use. With no types the
+// query matches every live entity.
+func (w *World) Query(types ...ComponentType) *Query {
+	key := queryKey(types)
+	if q, ok := w.queries[key]; ok {
+		return q
+	}
+
+	required := slices.Clone(types)
+	q := &Query{
+		required: required,
+		index:    make(map[EntityID]int),
+	}
+	for _, e := range w.entities {
+		if e.HasComponentsSlice(required) {
+			q.add(e)
+		}
+	}
+
+	if w.queries == nil {
+		w.queries = make(map[string]*Query)
+		w.queriesByType = make(map[ComponentType][]*Query)
+	}
+	w.queries[key] = q
+	for _, t := range required {
+		w.queriesByType[t] = append(w.queriesByType[t], q)
+	}
+	return q
+}
+
+// queryKey builds an order-independent cache key for a component type set.
+func queryKey(types []ComponentType) string {
+	keys := make([]string, len(types))
+	for i, t := range types {
+		keys[i] = string(t)
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	return strings.Join(keys, "\x00")
+}
+
+// componentAdded is called by Entity.AddComponent when t is new on e.
+func (w *World) componentAdded(e *Entity, t ComponentType) {
+	for _, q := range w.queriesByType[t] {
+		if e.HasComponentsSlice(q.required) {
+			q.add(e)
+		}
+	}
+}
+
+// componentRemoved is called by Entity.RemoveComponent after t was deleted from e.
+func (w *World) componentRemoved(e *Entity, t ComponentType) {
+	for _, q := range w.queriesByType[t] {
+		q.remove(e)
+	}
+}
+
+// indexEntity adds a newly attached entity to every matching query.
+func (w *World) indexEntity(e *Entity) {
+	for _, q := range w.queries {
+		if e.HasComponentsSlice(q.required) {
+			q.add(e)
+		}
+	}
+}
+
+// unindexEntity removes a despawning entity from every query.
+func (w *World) unindexEntity(e *Entity) {
+	for _, q := range w.queries {
+		q.remove(e)
+	}
+}
@@ -0,0 +1,186 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryMatchesExistingEntities(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	a := w.Spawn()
+	a.AddComponent(TestComponent{})
+	a.AddComponent(TestComponent2{})
+	b := w.Spawn()
+	b.AddComponent(TestComponent{})
+
+	q := w.Query(testComponentType, testComponent2Type)
+
+	assert.Equal([]*Entity{a}, q.Entities())
+	assert.Equal(2, w.Query(testComponentType).Len())
+	assert.Equal(2, w.Query().Len(), "Expected empty query to match every entity")
+}
+
+func TestQueryIsCached(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	q1 := w.Query(testComponentType, testComponent2Type)
+	q2 := w.Query(testComponent2Type, testComponentType)
+
+	assert.Same(q1, q2, "Expected the same query regardless of type order")
+}
+
+func TestQueryTracksComponentChanges(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	q := w.Query(testComponentType, testComponent2Type)
+
+	e := w.Spawn()
+	e.AddComponent(TestComponent{})
+	assert.Equal(0, q.Len())
+
+	e.AddComponent(TestComponent2{})
+	assert.Equal(1, q.Len())
+
+	e.AddComponent(TestComponent2{}) // replacing must not duplicate
+	assert.Equal(1, q.Len())
+
+	e.RemoveComponent(testComponentType)
+	assert.Equal(0, q.Len())
+}
+
+func TestQueryTracksSpawnAndDespawn(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	q := w.Query(testComponentType)
+
+	e := &Entity{}
+	e.AddComponent(TestComponent{})
+	w.Add(e)
+	other := w.Spawn()
+	other.AddComponent(TestComponent{})
+	assert.Equal(2, q.Len())
+
+	w.Despawn(e.ID)
+	assert.Equal([]*Entity{other}, q.Entities())
+
+	// Detached entities no longer update the world's queries.
+	e.AddComponent(TestComponent2{})
+	assert.Equal(0, w.Query(testComponent2Type).Len())
+}
+
+func TestUpdateSystemsForWorld(t *testing.T) {
+	assert := assert.New(t)
+	sm := &SystemManager{}
+	mockSystem1 := &MockSystem{RequiredComponents: []ComponentType{testComponentType}}
+	mockSystem2 := &MockSystem{RequiredComponents: []ComponentType{"UnknownComponent"}}
+	sm.AddSystem(mockSystem1)
+	sm.AddSystem(mockSystem2)
+
+	w := NewWorld()
+	e := w.Spawn()
+	e.AddComponent(TestComponent{})
+
+	err := sm.UpdateSystemsForWorld(w, w)
+
+	assert.Nil(err, "Expected no error from UpdateSystemsForWorld")
+	assert.True(mockSystem1.UpdateEntityCalled, "Expected UpdateEntity to be called on mock system 1")
+	assert.Equal(e, mockSystem1.UpdateEntityEntity)
+	assert.False(mockSystem2.UpdateEntityCalled, "Expected UpdateEntity not to be called on mock system 2")
+}
+
+// consumeSystem removes its required component from every entity it visits.
+type consumeSystem struct{ visited []*Entity }
+
+func (s *consumeSystem) UpdateSystem(any) error { return nil }
+
+func (s *consumeSystem) UpdateEntity(_ any, e *Entity) error {
+	s.visited = append(s.visited, e)
+	e.RemoveComponent(testComponentType)
+	return nil
+}
+
+func (s *consumeSystem) Requires() []ComponentType { return []ComponentType{testComponentType} }
+
+func TestUpdateSystemsForWorldToleratesRemovals(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	var all []*Entity
+	for i := 0; i < 3; i++ {
+		e := w.Spawn()
+		e.AddComponent(TestComponent{})
+		all = append(all, e)
+	}
+	sys := &consumeSystem{}
+	sm := &SystemManager{}
+	sm.AddSystem(sys)
+
+	assert.NoError(sm.UpdateSystemsForWorld(w, w))
+	assert.ElementsMatch(all, sys.visited, "Expected every entity visited once despite removals")
+	assert.Equal(0, w.Query(testComponentType).Len())
+}
+
+func TestQueryEachSkipsEntitiesThatStopMatching(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	a, b, c := w.Spawn(), w.Spawn(), w.Spawn()
+	for _, e := range []*Entity{a, b, c} {
+		e.AddComponent(TestComponent{})
+	}
+
+	var visited []*Entity
+	err := w.Query(testComponentType).Each(func(e *Entity) error {
+		visited = append(visited, e)
+		if e == a {
+			w.Despawn(c.ID)
+			b.RemoveComponent(testComponentType)
+			w.Spawn().AddComponent(TestComponent{})
+		}
+		return nil
+	})
+
+	assert.NoError(err)
+	assert.Equal([]*Entity{a}, visited)
+	assert.Equal(2, w.Query(testComponentType).Len())
+}
+
+// benchmarkWorld builds a world where one entity in ten matches the query.
+func benchmarkWorld(n int) *World {
+	w := NewWorld()
+	for i := 0; i < n; i++ {
+		e := w.Spawn()
+		e.AddComponent(TestComponent{})
+		if i%10 == 0 {
+			e.AddComponent(TestComponent2{})
+		}
+	}
+	return w
+}
+
+func BenchmarkHasComponentsScan(b *testing.B) {
+	w := benchmarkWorld(20000)
+	required := []ComponentType{testComponentType, testComponent2Type}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := 0
+		for _, e := range w.Entities() {
+			if e.HasComponentsSlice(required) {
+				n++
+			}
+		}
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	w := benchmarkWorld(20000)
+	q := w.Query(testComponentType, testComponent2Type)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := 0
+		for range q.Entities() {
+			n++
+		}
+	}
+}
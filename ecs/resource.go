@@ -0,0 +1,84 @@
+package ecs
+
+import (
+	"reflect"
+	"time"
+)
+
+// DeltaTime is the conventional resource for the length of the current
+// update step. Reusable systems such as particle.ParticleSystem use it, when
+// present, in place of their own fixed timestep.
+//
+//	ecs.SetResource(w, ecs.DeltaTime(time.Second/20))
+type DeltaTime time.Duration
+
+// Seconds returns the step length in seconds.
+func (d DeltaTime) Seconds() float64 {
+	return time.Duration(d).Seconds()
+}
+
+// SetResource stores v as w's singleton resource of type T, replacing any
+// previous one. Resources hold shared state that belongs to no entity, such
+// as the clock, an RNG, the camera or a world.Level, so generic systems can
+// find it without knowing the game's world struct.
+//
+// Resources are keyed by their exact Go type: a *Camera and a Camera are
+// different resources. Store pointers for state systems should mutate.
+// Resource lookups are safe during parallel system passes; SetResource and
+// RemoveResource should not race with them.
+func SetResource[T any](w *World, v T) {
+	w.resourceMu.Lock()
+	defer w.resourceMu.Unlock()
+	if w.resources == nil {
+		w.resources = make(map[reflect.Type]any)
+	}
+	w.resources[reflect.TypeFor[T]()] = v
+}
+
+// Resource returns w's resource of type T and whether it is set.
+//
+//	level, ok := ecs.Resource[*MyLevel](w)
+func Resource[T any](w *World) (T, bool) {
+	w.resourceMu.RLock()
+	defer w.resourceMu.RUnlock()
+	v, ok := w.resources[reflect.TypeFor[T]()]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return v.(T), true
+}
+
+// HasResource reports whether w has a resource of type T.
+func HasResource[T any](w *World) bool {
+	_, ok := Resource[T](w)
+	return ok
+}
+
+// RemoveResource deletes w's resource of type T, reporting whether it was set.
+func RemoveResource[T any](w *World) bool {
+	w.resourceMu.Lock()
+	defer w.resourceMu.Unlock()
+	t := reflect.TypeFor[T]()
+	if _, ok := w.resources[t]; !ok {
+		return false
+	}
+	delete(w.resources, t)
+	return true
+}
+
+// ResourceFrom looks up a resource of type T through the `world any` value a
+// system receives, which must be or provide a *World (see WorldFrom).
+//
+//	func (s *FollowCameraSystem) UpdateSimulation(world any) error {
+//	    cam, ok := ecs.ResourceFrom[*Camera](world)
+//	    ...
+//	}
+func ResourceFrom[T any](data any) (T, bool) {
+	w, ok := WorldFrom(data)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return Resource[T](w)
+}
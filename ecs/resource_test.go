@@ -0,0 +1,55 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCamera struct{ X, Y float64 }
+
+type testGame struct {
+	*World
+}
+
+func TestWorldResources(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	_, ok := Resource[*testCamera](w)
+	assert.False(ok, "Expected missing resource")
+
+	cam := &testCamera{X: 3}
+	SetResource(w, cam)
+	SetResource(w, DeltaTime(50*time.Millisecond))
+
+	got, ok := Resource[*testCamera](w)
+	assert.True(ok)
+	assert.Same(cam, got)
+	assert.False(HasResource[testCamera](w), "Expected value and pointer types to be distinct resources")
+
+	dt, _ := Resource[DeltaTime](w)
+	assert.Equal(0.05, dt.Seconds())
+
+	SetResource(w, &testCamera{X: 9})
+	got, _ = Resource[*testCamera](w)
+	assert.Equal(9.0, got.X, "Expected SetResource to replace the previous value")
+
+	assert.True(RemoveResource[*testCamera](w))
+	assert.False(RemoveResource[*testCamera](w))
+	assert.False(HasResource[*testCamera](w))
+}
+
+func TestResourceFrom(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	SetResource(w, DeltaTime(time.Second))
+
+	dt, ok := ResourceFrom[DeltaTime](testGame{w})
+	assert.True(ok, "Expected lookup through a WorldProvider")
+	assert.Equal(DeltaTime(time.Second), dt)
+
+	_, ok = ResourceFrom[DeltaTime]("not a world")
+	assert.False(ok)
+}
@@ -0,0 +1,395 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// saveMagic starts every binary save, followed by the format revision.
+// Revision 2 added hierarchy and relation links and revision 3 names and
+// tags; saves of earlier revisions still load.
+const (
+	saveMagic          = "MLES"
+	saveFormatRevision = 3
+)
+
+// Value tags of the binary save format.
+const (
+	tagNull byte = iota
+	tagFalse
+	tagTrue
+	tagInt    // zigzag varint
+	tagUint   // uvarint, for integers above math.MaxInt64
+	tagFloat  // 8-byte IEEE 754, little endian
+	tagString // string table index
+	tagArray  // count, values
+	tagObject // count, (key index, value) pairs
+)
+
+var errSaveTruncated = errors.New("ecs: binary save data truncated")
+
+// MarshalBinary implements encoding.BinaryMarshaler with a compact format:
+// every string (component names, field names, string values) is stored once
+// in a table and referenced by index, and numbers are varints or 8-byte
+// floats. Field values must be what encoding/json produces when decoding into
+// interface{} (with or without UseNumber), or Go integer and float types.
+func (d *SaveData) MarshalBinary() ([]byte, error) {
+	enc := saveEncoder{strings: make(map[string]uint64)}
+	enc.uvarint(uint64(len(d.Entities)))
+	for i := range d.Entities {
+		e := &d.Entities[i]
+		enc.uvarint(uint64(e.ID))
+		enc.str(e.Blueprint)
+		names := make([]string, 0, len(e.Components))
+		for name := range e.Components {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		enc.uvarint(uint64(len(names)))
+		for _, name := range names {
+			enc.str(name)
+			if err := enc.value(e.Components[name]); err != nil {
+				return nil, fmt.Errorf("ecs: encode entity %s component %s: %w", e.ID, name, err)
+			}
+		}
+		enc.uvarint(uint64(len(e.Values)))
+		for _, name := range e.Values {
+			enc.str(name)
+		}
+		enc.ids(e.Children)
+		relations := make([]string, 0, len(e.Relations))
+		for relation := range e.Relations {
+			relations = append(relations, string(relation))
+		}
+		sort.Strings(relations)
+		enc.uvarint(uint64(len(relations)))
+		for _, relation := range relations {
+			enc.str(relation)
+			enc.ids(e.Relations[Relation(relation)])
+		}
+		enc.str(e.Name)
+		enc.uvarint(uint64(len(e.Tags)))
+		for _, tag := range e.Tags {
+			enc.str(tag)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString(saveMagic)
+	out.WriteByte(saveFormatRevision)
+	out.Write(binary.AppendVarint(nil, int64(d.Version)))
+	out.Write(binary.AppendUvarint(nil, uint64(len(enc.table))))
+	for _, s := range enc.table {
+		out.Write(binary.AppendUvarint(nil, uint64(len(s))))
+		out.WriteString(s)
+	}
+	out.Write(enc.body)
+	return out.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler for data written by
+// MarshalBinary. Numbers are decoded as json.Number, as ReadJSON does.
+func (d *SaveData) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(saveMagic)) || len(data) < len(saveMagic)+1 {
+		return errors.New("ecs: not a binary save")
+	}
+	rev := data[len(saveMagic)]
+	if rev < 1 || rev > saveFormatRevision {
+		return fmt.Errorf("ecs: unsupported binary save revision %d", rev)
+	}
+	dec := saveDecoder{data: data[len(saveMagic)+1:]}
+	version := dec.varint()
+	tableLen := dec.uvarint()
+	if tableLen > uint64(len(dec.data)) {
+		return errSaveTruncated
+	}
+	dec.table = make([]string, tableLen)
+	for i := range dec.table {
+		n := dec.uvarint()
+		dec.table[i] = string(dec.bytes(n))
+	}
+
+	count := dec.uvarint()
+	if count > uint64(len(dec.data)) {
+		return errSaveTruncated
+	}
+	entities := make([]SavedEntity, count)
+	for i := range entities {
+		e := &entities[i]
+		e.ID = EntityID(dec.uvarint())
+		e.Blueprint = dec.str()
+		comps := dec.uvarint()
+		e.Components = make(map[string]map[string]interface{}, min(comps, uint64(len(dec.data))))
+		for j := uint64(0); j < comps && dec.err == nil; j++ {
+			name := dec.str()
+			fields, ok := dec.value().(map[string]interface{})
+			if !ok && dec.err == nil {
+				dec.err = fmt.Errorf("ecs: component %s is not an object", name)
+			}
+			e.Components[name] = fields
+		}
+		values := dec.uvarint()
+		for j := uint64(0); j < values && dec.err == nil; j++ {
+			e.Values = append(e.Values, dec.str())
+		}
+		if rev >= 2 {
+			e.Children = dec.ids()
+			relations := dec.uvarint()
+			for j := uint64(0); j < relations && dec.err == nil; j++ {
+				if e.Relations == nil {
+					e.Relations = make(map[Relation][]EntityID)
+				}
+				relation := Relation(dec.str())
+				e.Relations[relation] = dec.ids()
+			}
+		}
+		if rev >= 3 {
+			e.Name = dec.str()
+			tags := dec.uvarint()
+			for j := uint64(0); j < tags && dec.err == nil; j++ {
+				e.Tags = append(e.Tags, dec.str())
+			}
+		}
+		if dec.err != nil {
+			return dec.err
+		}
+	}
+	if dec.err != nil {
+		return dec.err
+	}
+	d.Version = int(version)
+	d.Entities = entities
+	return nil
+}
+
+type saveEncoder struct {
+	body    []byte
+	table   []string
+	strings map[string]uint64
+}
+
+func (e *saveEncoder) uvarint(v uint64) {
+	e.body = binary.AppendUvarint(e.body, v)
+}
+
+func (e *saveEncoder) str(s string) {
+	idx, ok := e.strings[s]
+	if !ok {
+		idx = uint64(len(e.table))
+		e.strings[s] = idx
+		e.table = append(e.table, s)
+	}
+	e.uvarint(idx)
+}
+
+func (e *saveEncoder) ids(ids []EntityID) {
+	e.uvarint(uint64(len(ids)))
+	for _, id := range ids {
+		e.uvarint(uint64(id))
+	}
+}
+
+func (e *saveEncoder) value(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.body = append(e.body, tagNull)
+	case bool:
+		if v {
+			e.body = append(e.body, tagTrue)
+		} else {
+			e.body = append(e.body, tagFalse)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			e.int(i)
+		} else if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			e.body = append(e.body, tagUint)
+			e.uvarint(u)
+		} else if f, err := v.Float64(); err == nil {
+			e.float(f)
+		} else {
+			return fmt.Errorf("invalid number %q", v)
+		}
+	case float64:
+		e.float(v)
+	case float32:
+		e.float(float64(v))
+	case int:
+		e.int(int64(v))
+	case int64:
+		e.int(v)
+	case int32:
+		e.int(int64(v))
+	case uint64:
+		e.body = append(e.body, tagUint)
+		e.uvarint(v)
+	case uint32:
+		e.int(int64(v))
+	case string:
+		e.body = append(e.body, tagString)
+		e.str(v)
+	case []interface{}:
+		e.body = append(e.body, tagArray)
+		e.uvarint(uint64(len(v)))
+		for _, item := range v {
+			if err := e.value(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		e.body = append(e.body, tagObject)
+		e.uvarint(uint64(len(keys)))
+		for _, k := range keys {
+			e.str(k)
+			if err := e.value(v[k]); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	return nil
+}
+
+func (e *saveEncoder) int(i int64) {
+	e.body = append(e.body, tagInt)
+	e.body = binary.AppendVarint(e.body, i)
+}
+
+func (e *saveEncoder) float(f float64) {
+	e.body = append(e.body, tagFloat)
+	e.body = binary.LittleEndian.AppendUint64(e.body, math.Float64bits(f))
+}
+
+// saveDecoder reads the binary format. The first error sticks and later reads
+// return zero values, so callers check err once per entity.
+type saveDecoder struct {
+	data  []byte
+	table []string
+	err   error
+}
+
+func (d *saveDecoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+	d.data = nil
+}
+
+func (d *saveDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail(errSaveTruncated)
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *saveDecoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail(errSaveTruncated)
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *saveDecoder) bytes(n uint64) []byte {
+	if n > uint64(len(d.data)) {
+		d.fail(errSaveTruncated)
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *saveDecoder) str() string {
+	idx := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if idx >= uint64(len(d.table)) {
+		d.fail(fmt.Errorf("ecs: string index %d out of range", idx))
+		return ""
+	}
+	return d.table[idx]
+}
+
+func (d *saveDecoder) ids() []EntityID {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.fail(errSaveTruncated)
+		return nil
+	}
+	var ids []EntityID
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		ids = append(ids, EntityID(d.uvarint()))
+	}
+	return ids
+}
+
+func (d *saveDecoder) value() interface{} {
+	tag := d.bytes(1)
+	if d.err != nil {
+		return nil
+	}
+	switch tag[0] {
+	case tagNull:
+		return nil
+	case tagFalse:
+		return false
+	case tagTrue:
+		return true
+	case tagInt:
+		return json.Number(strconv.FormatInt(d.varint(), 10))
+	case tagUint:
+		return json.Number(strconv.FormatUint(d.uvarint(), 10))
+	case tagFloat:
+		b := d.bytes(8)
+		if d.err != nil {
+			return nil
+		}
+		return json.Number(strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)), 'g', -1, 64))
+	case tagString:
+		return d.str()
+	case tagArray:
+		n := d.uvarint()
+		if n > uint64(len(d.data)) {
+			d.fail(errSaveTruncated)
+			return nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i] = d.value()
+		}
+		return items
+	case tagObject:
+		n := d.uvarint()
+		if n > uint64(len(d.data)) {
+			d.fail(errSaveTruncated)
+			return nil
+		}
+		obj := make(map[string]interface{}, n)
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			k := d.str()
+			obj[k] = d.value()
+		}
+		return obj
+	}
+	d.fail(fmt.Errorf("ecs: unknown value tag %d", tag[0]))
+	return nil
+}
@@ -0,0 +1,398 @@
+package ecs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Phase is a coarse run stage for systems. Every system in an earlier phase
+// runs before any system in a later phase; Before/After constraints order
+// systems within a phase.
+type Phase int
+
+const (
+	PreUpdate Phase = iota - 1
+	Update          // the zero value, used when no phase is given
+	PostUpdate
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PreUpdate:
+		return "PreUpdate"
+	case Update:
+		return "Update"
+	case PostUpdate:
+		return "PostUpdate"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// SystemOptions describes how a system is scheduled by a system manager.
+//
+// The zero value runs the system in the Update phase, in registration order
+// relative to other unconstrained systems.
+type SystemOptions struct {
+	// Name identifies the system so other systems can order themselves
+	// relative to it. Names must be unique within a manager.
+	Name string
+
+	// Phase is the run stage the system belongs to.
+	Phase Phase
+
+	// Before lists names of systems this system must run before.
+	Before []string
+
+	// After lists names of systems this system must run after.
+	After []string
+
+	// Reads and Writes declare the component types the system reads and
+	// writes. Declaring either list (even as an empty, non-nil slice) tells
+	// managers that support parallel execution that the system only touches
+	// those components on the entity it is given and that its own state is
+	// safe for concurrent use. Systems that declare nothing always run alone.
+	Reads  []ComponentType
+	Writes []ComponentType
+
+	// RunIf, if set, is evaluated with the world data at the start of each
+	// update pass; the system is skipped for that pass when it returns false.
+	RunIf func(world any) bool
+
+	// Every throttles the system to one in every Every passes (the first,
+	// then every Every-th after it). Global and per-entity passes are
+	// counted separately, so a system runs both halves on the same ticks
+	// when each pass is called once per tick. 0 or 1 runs every pass.
+	Every int
+}
+
+// DeclaresAccess reports whether the options declare a read/write set.
+func (o SystemOptions) DeclaresAccess() bool {
+	return o.Reads != nil || o.Writes != nil
+}
+
+// Conflicts reports whether two systems may not run at the same time: either
+// one does not declare its access, or one writes a component the other reads
+// or writes.
+func (o SystemOptions) Conflicts(other SystemOptions) bool {
+	if !o.DeclaresAccess() || !other.DeclaresAccess() {
+		return true
+	}
+	return overlaps(o.Writes, other.Writes) || overlaps(o.Writes, other.Reads) || overlaps(other.Writes, o.Reads)
+}
+
+// orderedWith reports whether o has a direct Before/After constraint with other.
+func (o SystemOptions) orderedWith(other SystemOptions) bool {
+	return (other.Name != "" && (slices.Contains(o.Before, other.Name) || slices.Contains(o.After, other.Name))) ||
+		(o.Name != "" && (slices.Contains(other.Before, o.Name) || slices.Contains(other.After, o.Name)))
+}
+
+func overlaps(a, b []ComponentType) bool {
+	for _, t := range a {
+		if slices.Contains(b, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// OptionedSystem can be implemented by any system (ecs, simulation or render)
+// to declare its own scheduling options. System managers use them when the
+// system is added with AddSystem.
+type OptionedSystem interface {
+	SystemOptions() SystemOptions
+}
+
+// OptionsFor returns the options a system declares via [OptionedSystem], or
+// the zero SystemOptions.
+func OptionsFor(system any) SystemOptions {
+	if o, ok := system.(OptionedSystem); ok {
+		return o.SystemOptions()
+	}
+	return SystemOptions{}
+}
+
+// CycleError is returned when Before/After constraints cannot be satisfied.
+type CycleError struct {
+	// Systems lists the systems that could not be ordered.
+	Systems []string
+}
+
+func (e *CycleError) Error() string {
+	return "ecs: system ordering cycle involving " + strings.Join(e.Systems, ", ")
+}
+
+// Schedule resolves the run order of a list of systems from their
+// [SystemOptions]. It is shared by ecs.SystemManager and the simulation and
+// client system managers; each keeps its systems in registration order and
+// asks the Schedule which order to run them in.
+//
+// The zero value is an empty schedule.
+type Schedule struct {
+	options  []SystemOptions
+	disabled []bool
+	order    []int
+	stages   [][]int
+	err      error
+	resolved bool
+	passes   [EntityPass + 1]int // passes started, per Pass, for Every
+}
+
+// Add appends a system's options. The system's index is its registration order.
+func (s *Schedule) Add(opts SystemOptions) {
+	s.options = append(s.options, opts)
+	s.disabled = append(s.disabled, false)
+	s.resolved = false
+}
+
+// SetEnabled enables or disables every system registered under name. A
+// disabled system keeps its place in the order but is skipped by all passes.
+// Returns an error if no system has that name.
+func (s *Schedule) SetEnabled(name string, enabled bool) error {
+	found := false
+	for i, o := range s.options {
+		if o.Name == name {
+			s.disabled[i] = !enabled
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("ecs: no system named %q", name)
+	}
+	return nil
+}
+
+// Enabled reports whether the system registered under name is enabled.
+// Unknown names report false.
+func (s *Schedule) Enabled(name string) bool {
+	for i, o := range s.options {
+		if o.Name == name {
+			return !s.disabled[i]
+		}
+	}
+	return false
+}
+
+// Runnable starts a pass and returns the registration indices, in run order,
+// of the systems that run in it: enabled systems whose RunIf accepts world
+// and whose Every interval falls on this pass. Managers call it once per
+// global or per-entity pass.
+func (s *Schedule) Runnable(pass Pass, world any) ([]int, error) {
+	order, err := s.Order()
+	if err != nil {
+		return nil, err
+	}
+	if !s.conditional() {
+		return order, nil
+	}
+	n := s.nextPass(pass)
+	runnable := make([]int, 0, len(order))
+	for _, i := range order {
+		if s.runs(i, n, world) {
+			runnable = append(runnable, i)
+		}
+	}
+	return runnable, nil
+}
+
+// RunnableStages is Runnable for managers that run Stages: it returns the
+// stages with the systems that do not run in this pass removed, dropping
+// stages left empty.
+func (s *Schedule) RunnableStages(pass Pass, world any) ([][]int, error) {
+	stages, err := s.Stages()
+	if err != nil {
+		return nil, err
+	}
+	if !s.conditional() {
+		return stages, nil
+	}
+	n := s.nextPass(pass)
+	runnable := make([][]int, 0, len(stages))
+	for _, stage := range stages {
+		var kept []int
+		for _, i := range stage {
+			if s.runs(i, n, world) {
+				kept = append(kept, i)
+			}
+		}
+		if len(kept) > 0 {
+			runnable = append(runnable, kept)
+		}
+	}
+	return runnable, nil
+}
+
+// RunsOnce reports whether system i may run for world outside a counted
+// pass, e.g. for a single entity: it must be enabled and accept RunIf.
+// Every is not applied.
+func (s *Schedule) RunsOnce(i int, world any) bool {
+	return !s.disabled[i] && (s.options[i].RunIf == nil || s.options[i].RunIf(world))
+}
+
+// conditional reports whether any system can be skipped, so unconditional
+// schedules keep returning the cached order without allocating.
+func (s *Schedule) conditional() bool {
+	for i, o := range s.options {
+		if s.disabled[i] || o.RunIf != nil || o.Every > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schedule) nextPass(pass Pass) int {
+	n := s.passes[pass]
+	s.passes[pass]++
+	return n
+}
+
+func (s *Schedule) runs(i, pass int, world any) bool {
+	if every := s.options[i].Every; every > 1 && pass%every != 0 {
+		return false
+	}
+	return s.RunsOnce(i, world)
+}
+
+// Len returns the number of registered systems.
+func (s *Schedule) Len() int {
+	return len(s.options)
+}
+
+// Options returns the options for the system at registration index i.
+func (s *Schedule) Options(i int) SystemOptions {
+	return s.options[i]
+}
+
+// Order returns registration indices in run order: by phase, then by a
+// topological sort of Before/After constraints, breaking ties by registration
+// order. The result is cached until the next Add.
+//
+// Constraints naming systems that are not registered are ignored, so optional
+// plugins can reference each other. Duplicate names, constraints that point
+// from a later phase to an earlier one, and cycles are reported as errors.
+func (s *Schedule) Order() ([]int, error) {
+	if !s.resolved {
+		s.order, s.err = resolveOrder(s.options)
+		s.stages = nil
+		s.resolved = true
+	}
+	return s.order, s.err
+}
+
+// Stages groups the run order into stages of systems that may run at the
+// same time. A stage is a maximal run of consecutive systems (in Order) that
+// share a phase, declare their access, do not conflict with each other and
+// have no direct Before/After constraint between them. Systems that declare
+// no access always form a stage of their own. Running the stages in order,
+// each stage's members in any order or concurrently, is equivalent to
+// running the systems in Order.
+func (s *Schedule) Stages() ([][]int, error) {
+	order, err := s.Order()
+	if err != nil {
+		return nil, err
+	}
+	if s.stages != nil || len(order) == 0 {
+		return s.stages, nil
+	}
+	var current []int
+	for _, i := range order {
+		if len(current) > 0 && !s.joinsStage(current, i) {
+			s.stages = append(s.stages, current)
+			current = nil
+		}
+		current = append(current, i)
+	}
+	s.stages = append(s.stages, current)
+	return s.stages, nil
+}
+
+func (s *Schedule) joinsStage(stage []int, i int) bool {
+	opts := s.options[i]
+	for _, j := range stage {
+		other := s.options[j]
+		if other.Phase != opts.Phase || opts.Conflicts(other) || opts.orderedWith(other) {
+			return false
+		}
+	}
+	return true
+}
+
+func resolveOrder(opts []SystemOptions) ([]int, error) {
+	n := len(opts)
+	byName := make(map[string]int, n)
+	for i, o := range opts {
+		if o.Name == "" {
+			continue
+		}
+		if _, dup := byName[o.Name]; dup {
+			return nil, fmt.Errorf("ecs: duplicate system name %q", o.Name)
+		}
+		byName[o.Name] = i
+	}
+
+	succ := make([][]int, n)
+	indeg := make([]int, n)
+	addEdge := func(from, to int) error {
+		if opts[from].Phase > opts[to].Phase {
+			return fmt.Errorf("ecs: system %s (%s) cannot run before %s (%s)",
+				systemLabel(opts, from), opts[from].Phase, systemLabel(opts, to), opts[to].Phase)
+		}
+		if opts[from].Phase == opts[to].Phase {
+			succ[from] = append(succ[from], to)
+			indeg[to]++
+		}
+		return nil
+	}
+	for i, o := range opts {
+		for _, name := range o.Before {
+			if j, ok := byName[name]; ok {
+				if err := addEdge(i, j); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, name := range o.After {
+			if j, ok := byName[name]; ok {
+				if err := addEdge(j, i); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	order := make([]int, 0, n)
+	done := make([]bool, n)
+	for len(order) < n {
+		pick := -1
+		for i := 0; i < n; i++ {
+			if done[i] || indeg[i] > 0 {
+				continue
+			}
+			if pick < 0 || opts[i].Phase < opts[pick].Phase {
+				pick = i
+			}
+		}
+		if pick < 0 {
+			var stuck []string
+			for i := 0; i < n; i++ {
+				if !done[i] {
+					stuck = append(stuck, systemLabel(opts, i))
+				}
+			}
+			return nil, &CycleError{Systems: stuck}
+		}
+		done[pick] = true
+		order = append(order, pick)
+		for _, j := range succ[pick] {
+			indeg[j]--
+		}
+	}
+	return order, nil
+}
+
+func systemLabel(opts []SystemOptions, i int) string {
+	if opts[i].Name != "" {
+		return fmt.Sprintf("%q", opts[i].Name)
+	}
+	return fmt.Sprintf("#%d", i)
+}
@@ -0,0 +1,144 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// orderSystem records its name into a shared log when updated.
+type orderSystem struct {
+	name string
+	log  *[]string
+	opts SystemOptions
+}
+
+func (s *orderSystem) UpdateSystem(data any) error {
+	*s.log = append(*s.log, s.name)
+	return nil
+}
+
+func (s *orderSystem) UpdateEntity(data any, entity *Entity) error { return nil }
+
+func (s *orderSystem) Requires() []ComponentType { return nil }
+
+func (s *orderSystem) SystemOptions() SystemOptions { return s.opts }
+
+func TestScheduleDefaultsToRegistrationOrder(t *testing.T) {
+	assert := assert.New(t)
+	var s Schedule
+	s.Add(SystemOptions{})
+	s.Add(SystemOptions{})
+	s.Add(SystemOptions{})
+
+	order, err := s.Order()
+
+	assert.Nil(err)
+	assert.Equal([]int{0, 1, 2}, order)
+}
+
+func TestSystemManagerOrdersByPhaseAndConstraints(t *testing.T) {
+	assert := assert.New(t)
+	var log []string
+	sm := &SystemManager{}
+	sm.AddSystem(&orderSystem{name: "render", log: &log, opts: SystemOptions{Name: "render", Phase: PostUpdate}})
+	sm.AddSystem(&orderSystem{name: "movement", log: &log, opts: SystemOptions{Name: "movement", After: []string{"input"}}})
+	sm.AddSystem(&orderSystem{name: "collision", log: &log, opts: SystemOptions{Name: "collision", After: []string{"movement"}}})
+	sm.AddSystem(&orderSystem{name: "input", log: &log, opts: SystemOptions{Name: "input", Phase: PreUpdate}})
+	sm.AddSystemWithOptions(&orderSystem{name: "ai", log: &log}, SystemOptions{Name: "ai", Before: []string{"movement", "missing"}})
+
+	err := sm.UpdateSystems(nil)
+
+	assert.Nil(err)
+	assert.Equal([]string{"input", "ai", "movement", "collision", "render"}, log)
+}
+
+func TestScheduleDetectsCycles(t *testing.T) {
+	assert := assert.New(t)
+	var s Schedule
+	s.Add(SystemOptions{Name: "a", After: []string{"b"}})
+	s.Add(SystemOptions{Name: "b", After: []string{"a"}})
+	s.Add(SystemOptions{Name: "c"})
+
+	_, err := s.Order()
+
+	var cycle *CycleError
+	assert.ErrorAs(err, &cycle)
+	assert.Equal([]string{`"a"`, `"b"`}, cycle.Systems)
+}
+
+func TestScheduleRejectsBackwardsPhaseConstraint(t *testing.T) {
+	var s Schedule
+	s.Add(SystemOptions{Name: "late", Phase: PostUpdate, Before: []string{"early"}})
+	s.Add(SystemOptions{Name: "early"})
+
+	_, err := s.Order()
+
+	assert.Error(t, err)
+}
+
+func TestScheduleRejectsDuplicateNames(t *testing.T) {
+	sm := &SystemManager{}
+	sm.AddSystemWithOptions(&MockSystem{}, SystemOptions{Name: "dup"})
+	sm.AddSystemWithOptions(&MockSystem{}, SystemOptions{Name: "dup"})
+
+	assert.Error(t, sm.Resolve())
+	assert.Error(t, sm.UpdateSystems(nil), "Expected update to surface the ordering error")
+}
+
+func TestSystemManagerEnableDisable(t *testing.T) {
+	assert := assert.New(t)
+	var log []string
+	sm := &SystemManager{}
+	sm.AddSystem(&orderSystem{name: "weather", log: &log, opts: SystemOptions{Name: "weather"}})
+	sm.AddSystem(&orderSystem{name: "ai", log: &log, opts: SystemOptions{Name: "ai"}})
+
+	assert.Nil(sm.SetEnabled("weather", false))
+	assert.False(sm.Enabled("weather"))
+	assert.True(sm.Enabled("ai"))
+	assert.Nil(sm.UpdateSystems(nil))
+	assert.Equal([]string{"ai"}, log)
+
+	assert.Nil(sm.SetEnabled("weather", true))
+	assert.Nil(sm.UpdateSystems(nil))
+	assert.Equal([]string{"ai", "weather", "ai"}, log)
+
+	assert.Error(sm.SetEnabled("missing", false))
+}
+
+func TestSystemManagerRunIfAndEvery(t *testing.T) {
+	assert := assert.New(t)
+	var log []string
+	paused := false
+	sm := &SystemManager{}
+	sm.AddSystem(&orderSystem{name: "ai", log: &log, opts: SystemOptions{
+		RunIf: func(world any) bool { return !paused },
+	}})
+	sm.AddSystem(&orderSystem{name: "senses", log: &log, opts: SystemOptions{Every: 3}})
+
+	for tick := 0; tick < 6; tick++ {
+		paused = tick == 1
+		assert.Nil(sm.UpdateSystems(nil))
+	}
+	assert.Equal([]string{"ai", "senses", "ai", "ai", "senses", "ai", "ai"}, log)
+}
+
+func TestScheduleRunnableStagesDropsSkippedSystems(t *testing.T) {
+	assert := assert.New(t)
+	access := []ComponentType{}
+	var s Schedule
+	s.Add(SystemOptions{Name: "a", Reads: access})
+	s.Add(SystemOptions{Name: "b", Reads: access, Every: 2})
+	s.Add(SystemOptions{Name: "c"})
+
+	stages, err := s.RunnableStages(EntityPass, nil)
+	assert.Nil(err)
+	assert.Equal([][]int{{0, 1}, {2}}, stages)
+
+	stages, _ = s.RunnableStages(EntityPass, nil)
+	assert.Equal([][]int{{0}, {2}}, stages)
+
+	s.SetEnabled("c", false)
+	stages, _ = s.RunnableStages(EntityPass, nil)
+	assert.Equal([][]int{{0, 1}}, stages)
+}
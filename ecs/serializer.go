@@ -0,0 +1,322 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// SaveData is the serializable state of every entity in a World.
+type SaveData struct {
+	// Version is the EntitySerializer version the data was written with.
+	Version  int           `json:"version"`
+	Entities []SavedEntity `json:"entities"`
+}
+
+// SavedEntity is one entity in a SaveData.
+type SavedEntity struct {
+	// ID is restored as-is, so EntityID values stored in components keep
+	// pointing at the right entities.
+	ID        EntityID `json:"id"`
+	Blueprint string   `json:"blueprint,omitempty"`
+
+	// Name is the entity's unique name in its World (see World.SetName).
+	Name string `json:"name,omitempty"`
+
+	// Tags lists the names of the entity's tags in creation order. Tags are
+	// saved by name because their bits depend on the order NewTag was called.
+	Tags []string `json:"tags,omitempty"`
+
+	// Components maps JSONFactory registry names to the component's fields.
+	// Numbers are json.Number values.
+	Components map[string]map[string]interface{} `json:"components"`
+
+	// Values lists components that were stored on the entity as values rather
+	// than pointers, so they are restored the same way.
+	Values []string `json:"values,omitempty"`
+
+	// Children lists the entity's children (see World.SetParent) in the
+	// order they were attached.
+	Children []EntityID `json:"children,omitempty"`
+
+	// Relations lists the entities the entity links to under each relation
+	// (see World.Relate), in the order the links were added.
+	Relations map[Relation][]EntityID `json:"relations,omitempty"`
+}
+
+// Migration upgrades one saved entity from one save version to the next,
+// e.g. renaming or reshaping a component's fields.
+type Migration func(e *SavedEntity) error
+
+// EntitySerializer saves and restores every entity in a World. Components are
+// looked up in a JSONFactory's registry, so a component type can be saved if
+// its constructor is registered; the registry name is what is written.
+//
+// Tags, unique names, parent/child links and relations between saved
+// entities are saved with them; links are restored once every entity has
+// been added.
+//
+// Data is written with the serializer's version. When older data is restored,
+// migrations registered with AddMigration run in order for every step from
+// the data's version up to the current one; steps without a migration leave
+// the entities unchanged.
+type EntitySerializer struct {
+	factory    *JSONFactory
+	version    int
+	migrations map[int]Migration
+	transient  map[ComponentType]bool
+	names      map[ComponentType]string // registry name per component type
+	namesFrom  int                      // registry size names was built from
+}
+
+// NewEntitySerializer creates a serializer for entities whose components are
+// registered with f. version is written into every save.
+func NewEntitySerializer(f *JSONFactory, version int) *EntitySerializer {
+	return &EntitySerializer{
+		factory:    f,
+		version:    version,
+		migrations: make(map[int]Migration),
+		transient:  make(map[ComponentType]bool),
+	}
+}
+
+// Version returns the version written into saves.
+func (s *EntitySerializer) Version() int {
+	return s.version
+}
+
+// AddMigration registers fn to upgrade entities saved with version from to version from+1.
+func (s *EntitySerializer) AddMigration(from int, fn Migration) {
+	s.migrations[from] = fn
+}
+
+// Transient marks component types that are not saved, such as caches rebuilt
+// at runtime. Any other component without a registered constructor makes Save fail.
+func (s *EntitySerializer) Transient(types ...ComponentType) {
+	for _, t := range types {
+		s.transient[t] = true
+	}
+}
+
+// Save captures every entity in w.
+func (s *EntitySerializer) Save(w *World) (*SaveData, error) {
+	names := s.componentNames()
+	data := &SaveData{Version: s.version, Entities: make([]SavedEntity, 0, w.Len())}
+	for _, e := range w.Entities() {
+		saved := SavedEntity{ID: e.ID, Blueprint: e.Blueprint, Name: w.Name(e.ID), Components: make(map[string]map[string]interface{}, len(e.Components))}
+		for _, tag := range e.tags.Tags() {
+			saved.Tags = append(saved.Tags, tag.String())
+		}
+		for t, c := range e.Components {
+			if s.transient[t] {
+				continue
+			}
+			name, ok := names[t]
+			if !ok {
+				return nil, fmt.Errorf("ecs: save entity %s: component %s not registered", e.ID, t)
+			}
+			fields, err := componentFields(c)
+			if err != nil {
+				return nil, fmt.Errorf("ecs: save entity %s: component %s: %w", e.ID, t, err)
+			}
+			saved.Components[name] = fields
+			if reflect.ValueOf(c).Kind() != reflect.Pointer {
+				saved.Values = append(saved.Values, name)
+			}
+		}
+		sort.Strings(saved.Values)
+		saved.Children = slices.Clone(w.Children(e.ID))
+		for _, relation := range w.Relations(e.ID) {
+			if saved.Relations == nil {
+				saved.Relations = make(map[Relation][]EntityID)
+			}
+			saved.Relations[relation] = slices.Clone(w.Related(e.ID, relation))
+		}
+		data.Entities = append(data.Entities, saved)
+	}
+	return data, nil
+}
+
+// Restore migrates data to the current version and adds its entities to w
+// under their saved IDs. w would normally be empty.
+func (s *EntitySerializer) Restore(w *World, data *SaveData) error {
+	if err := s.migrate(data); err != nil {
+		return err
+	}
+	for i := range data.Entities {
+		saved := &data.Entities[i]
+		e, err := s.restoreEntity(saved)
+		if err != nil {
+			return err
+		}
+		if err := w.AddWithID(saved.ID, e); err != nil {
+			return fmt.Errorf("ecs: restore entity %s: %w", saved.ID, err)
+		}
+		if err := w.SetName(saved.ID, saved.Name); err != nil {
+			return fmt.Errorf("ecs: restore entity %s: %w", saved.ID, err)
+		}
+	}
+	for i := range data.Entities {
+		if err := restoreLinks(w, &data.Entities[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreLinks re-creates the hierarchy and relation links saved with an entity.
+func restoreLinks(w *World, saved *SavedEntity) error {
+	for _, child := range saved.Children {
+		if err := w.SetParent(child, saved.ID); err != nil {
+			return fmt.Errorf("ecs: restore entity %s: %w", saved.ID, err)
+		}
+	}
+	relations := make([]Relation, 0, len(saved.Relations))
+	for relation := range saved.Relations {
+		relations = append(relations, relation)
+	}
+	slices.Sort(relations)
+	for _, relation := range relations {
+		for _, to := range saved.Relations[relation] {
+			if err := w.Relate(saved.ID, relation, to); err != nil {
+				return fmt.Errorf("ecs: restore entity %s: %w", saved.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON saves w as indented JSON.
+func (s *EntitySerializer) WriteJSON(out io.Writer, w *World) error {
+	data, err := s.Save(w)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// ReadJSON restores entities written by WriteJSON into w.
+func (s *EntitySerializer) ReadJSON(in io.Reader, w *World) error {
+	dec := json.NewDecoder(in)
+	dec.UseNumber()
+	var data SaveData
+	if err := dec.Decode(&data); err != nil {
+		return fmt.Errorf("ecs: failed to parse save data: %w", err)
+	}
+	return s.Restore(w, &data)
+}
+
+// WriteBinary saves w in the compact binary form (see SaveData.MarshalBinary).
+func (s *EntitySerializer) WriteBinary(out io.Writer, w *World) error {
+	data, err := s.Save(w)
+	if err != nil {
+		return err
+	}
+	raw, err := data.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(raw)
+	return err
+}
+
+// ReadBinary restores entities written by WriteBinary into w.
+func (s *EntitySerializer) ReadBinary(in io.Reader, w *World) error {
+	raw, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	var data SaveData
+	if err := data.UnmarshalBinary(raw); err != nil {
+		return err
+	}
+	return s.Restore(w, &data)
+}
+
+func (s *EntitySerializer) migrate(data *SaveData) error {
+	if data.Version > s.version {
+		return fmt.Errorf("ecs: save version %d is newer than supported version %d", data.Version, s.version)
+	}
+	for v := data.Version; v < s.version; v++ {
+		migration := s.migrations[v]
+		if migration == nil {
+			continue
+		}
+		for i := range data.Entities {
+			if err := migration(&data.Entities[i]); err != nil {
+				return fmt.Errorf("ecs: migrate entity %s from version %d: %w", data.Entities[i].ID, v, err)
+			}
+		}
+	}
+	data.Version = s.version
+	return nil
+}
+
+func (s *EntitySerializer) restoreEntity(saved *SavedEntity) (*Entity, error) {
+	e := &Entity{Blueprint: saved.Blueprint}
+	for _, name := range saved.Tags {
+		tag, err := newTag(name)
+		if err != nil {
+			return nil, fmt.Errorf("ecs: restore entity %s: %w", saved.ID, err)
+		}
+		e.SetTag(tag)
+	}
+	for name, fields := range saved.Components {
+		c, err := s.factory.CreateComponent(name, fields)
+		if err != nil {
+			return nil, fmt.Errorf("ecs: restore entity %s: component %s: %w", saved.ID, name, err)
+		}
+		if slices.Contains(saved.Values, name) {
+			if v, ok := reflect.ValueOf(c).Elem().Interface().(Component); ok {
+				c = v
+			}
+		}
+		e.AddComponent(c)
+	}
+	return e, nil
+}
+
+// componentNames maps each registered constructor's component type to its
+// registry name, rebuilding when more components have been registered. When
+// several names produce the same type, the first in sorted order wins so
+// output is stable.
+func (s *EntitySerializer) componentNames() map[ComponentType]string {
+	if s.names != nil && s.namesFrom == len(s.factory.registry) {
+		return s.names
+	}
+	s.namesFrom = len(s.factory.registry)
+	registered := make([]string, 0, len(s.factory.registry))
+	for name := range s.factory.registry {
+		registered = append(registered, name)
+	}
+	sort.Strings(registered)
+	s.names = make(map[ComponentType]string, len(registered))
+	for _, name := range registered {
+		t := s.factory.registry[name]().GetType()
+		if _, taken := s.names[t]; !taken {
+			s.names[t] = name
+		}
+	}
+	return s.names
+}
+
+// componentFields converts a component to the generic form CreateComponent accepts.
+func componentFields(c Component) (map[string]interface{}, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("component must encode as a JSON object: %w", err)
+	}
+	return fields, nil
+}
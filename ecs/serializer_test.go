@@ -0,0 +1,243 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type followComponent struct {
+	Target EntityID
+	Speed  float64
+}
+
+func (c *followComponent) GetType() ComponentType { return "Follow" }
+
+type cellComponent struct{ X, Y int }
+
+func (c cellComponent) GetType() ComponentType { return "Cell" }
+
+type renderCacheComponent struct{}
+
+func (c *renderCacheComponent) GetType() ComponentType { return "RenderCache" }
+
+func newSaveFactory() *JSONFactory {
+	f := NewJSONFactory()
+	f.RegisterComponent("Follow", func() Component { return &followComponent{} })
+	f.RegisterComponent("Cell", func() Component { return &cellComponent{} })
+	f.RegisterComponent("Stats", func() Component { return &statsComponent{} })
+	return f
+}
+
+func buildSaveWorld() (*World, *Entity, *Entity) {
+	w := NewWorld()
+	w.Despawn(w.Spawn().ID) // make sure restored IDs carry a non-zero generation
+	leader := w.Spawn()
+	leader.Blueprint = "leader"
+	leader.AddComponent(cellComponent{X: 3, Y: -4})
+	leader.AddComponent(&statsComponent{Health: 7, Speed: 0.1, Tags: []string{"boss"}, Loot: map[string]int{"gold": 9}})
+	leader.AddComponent(&renderCacheComponent{})
+	follower := w.Spawn()
+	follower.AddComponent(&followComponent{Target: leader.ID, Speed: 1.25})
+	return w, leader, follower
+}
+
+func assertRestored(t *testing.T, w *World, leaderID, followerID EntityID) {
+	t.Helper()
+	assert := assert.New(t)
+	assert.Equal(2, w.Len())
+	leader := w.Get(leaderID)
+	follower := w.Get(followerID)
+	if !assert.NotNil(leader) || !assert.NotNil(follower) {
+		return
+	}
+	assert.Equal("leader", leader.Blueprint)
+	assert.Equal(cellComponent{X: 3, Y: -4}, Get[cellComponent](leader), "Expected value components to be restored as values")
+	assert.Equal(&statsComponent{Health: 7, Speed: 0.1, Tags: []string{"boss"}, Loot: map[string]int{"gold": 9}}, leader.GetComponent("Stats"))
+	assert.False(leader.HasComponent("RenderCache"))
+	follow := Get[*followComponent](follower)
+	assert.Equal(leader, w.Get(follow.Target), "Expected entity references to survive")
+	assert.Equal(1.25, follow.Speed)
+	assert.Equal(1, w.Query("Cell").Len(), "Expected restored entities to be indexed")
+}
+
+func TestEntitySerializerJSONRoundTrip(t *testing.T) {
+	w, leader, follower := buildSaveWorld()
+	s := NewEntitySerializer(newSaveFactory(), 1)
+	s.Transient("RenderCache")
+	var buf bytes.Buffer
+
+	assert.Nil(t, s.WriteJSON(&buf, w))
+	restored := NewWorld()
+	assert.Nil(t, s.ReadJSON(&buf, restored))
+
+	assertRestored(t, restored, leader.ID, follower.ID)
+}
+
+func TestEntitySerializerBinaryRoundTrip(t *testing.T) {
+	w, leader, follower := buildSaveWorld()
+	s := NewEntitySerializer(newSaveFactory(), 1)
+	s.Transient("RenderCache")
+	var bin, js bytes.Buffer
+
+	assert.Nil(t, s.WriteBinary(&bin, w))
+	assert.Nil(t, s.WriteJSON(&js, w))
+	assert.Less(t, bin.Len(), js.Len()/2, "Expected the binary form to be compact")
+	restored := NewWorld()
+	assert.Nil(t, s.ReadBinary(&bin, restored))
+
+	assertRestored(t, restored, leader.ID, follower.ID)
+}
+
+func TestEntitySerializerRejectsUnregisteredComponents(t *testing.T) {
+	w, _, _ := buildSaveWorld()
+	s := NewEntitySerializer(newSaveFactory(), 1)
+
+	_, err := s.Save(w)
+
+	assert.ErrorContains(t, err, "component RenderCache not registered")
+}
+
+func TestEntitySerializerMigrates(t *testing.T) {
+	assert := assert.New(t)
+	old := `{"version": 1, "entities": [{"id": "0.1", "components": {"Cell": {"Col": 2, "Row": 5}}}]}`
+	s := NewEntitySerializer(newSaveFactory(), 3)
+	var steps []int
+	s.AddMigration(1, func(e *SavedEntity) error {
+		steps = append(steps, 1)
+		cell := e.Components["Cell"]
+		e.Components["Cell"] = map[string]interface{}{"X": cell["Col"], "Y": cell["Row"]}
+		e.Values = append(e.Values, "Cell")
+		return nil
+	})
+	w := NewWorld()
+
+	assert.Nil(s.ReadJSON(bytes.NewBufferString(old), w))
+
+	assert.Equal([]int{1}, steps, "Expected only the registered step to run")
+	id, _ := ParseEntityID("0.1")
+	assert.Equal(cellComponent{X: 2, Y: 5}, Get[cellComponent](w.Get(id)))
+
+	err := s.Restore(NewWorld(), &SaveData{Version: 4})
+	assert.ErrorContains(err, "newer than supported")
+}
+
+func TestSaveDataBinaryPreservesLargeIntegers(t *testing.T) {
+	assert := assert.New(t)
+	data := &SaveData{Version: -2, Entities: []SavedEntity{{
+		ID: newEntityID(7, math.MaxUint32),
+		Components: map[string]map[string]interface{}{"Big": {
+			"u":   json.Number("18446744073709551615"),
+			"i":   json.Number("-9007199254740993"),
+			"f":   3.5,
+			"nil": nil,
+			"arr": []interface{}{true, "s"},
+		}},
+	}}}
+
+	raw, err := data.MarshalBinary()
+	assert.Nil(err)
+	var decoded SaveData
+	assert.Nil(decoded.UnmarshalBinary(raw))
+
+	assert.Equal(-2, decoded.Version)
+	assert.Equal(data.Entities[0].ID, decoded.Entities[0].ID)
+	big := decoded.Entities[0].Components["Big"]
+	assert.Equal(json.Number("18446744073709551615"), big["u"])
+	assert.Equal(json.Number("-9007199254740993"), big["i"])
+	assert.Equal(json.Number("3.5"), big["f"])
+	assert.Nil(big["nil"])
+	assert.Equal([]interface{}{true, "s"}, big["arr"])
+
+	assert.Error(decoded.UnmarshalBinary(raw[:len(raw)-3]), "Expected truncated data to fail")
+}
+
+// saveRoundTrips returns functions that save one world with s and restore
+// it into another, per save format.
+func saveRoundTrips(s *EntitySerializer) map[string]func(from, to *World) error {
+	return map[string]func(from, to *World) error{
+		"json": func(from, to *World) error {
+			var buf bytes.Buffer
+			if err := s.WriteJSON(&buf, from); err != nil {
+				return err
+			}
+			return s.ReadJSON(&buf, to)
+		},
+		"binary": func(from, to *World) error {
+			var buf bytes.Buffer
+			if err := s.WriteBinary(&buf, from); err != nil {
+				return err
+			}
+			return s.ReadBinary(&buf, to)
+		},
+	}
+}
+
+func TestEntitySerializerRestoresLinks(t *testing.T) {
+	w := NewWorld()
+	ship, turretA, turretB, crew := w.Spawn(), w.Spawn(), w.Spawn(), w.Spawn()
+	assert.Nil(t, w.SetParent(turretB.ID, ship.ID))
+	assert.Nil(t, w.SetParent(turretA.ID, ship.ID))
+	assert.Nil(t, w.Relate(crew.ID, "mans", turretA.ID))
+	assert.Nil(t, w.Relate(crew.ID, "mans", turretB.ID))
+	assert.Nil(t, w.Relate(crew.ID, "owned_by", ship.ID))
+	s := NewEntitySerializer(newSaveFactory(), 1)
+
+	for name, roundTrip := range saveRoundTrips(s) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			restored := NewWorld()
+			assert.Nil(roundTrip(w, restored))
+
+			assert.Equal([]EntityID{turretB.ID, turretA.ID}, restored.Children(ship.ID), "Expected children in attach order")
+			assert.Equal(ship.ID, restored.Parent(turretA.ID))
+			assert.Equal([]EntityID{turretA.ID, turretB.ID}, restored.Related(crew.ID, "mans"))
+			assert.Equal([]EntityID{crew.ID}, restored.RelatedTo(ship.ID, "owned_by"))
+
+			restored.Despawn(ship.ID)
+			assert.False(restored.Alive(turretA.ID), "Expected despawning the parent to despawn restored children")
+		})
+	}
+}
+
+func TestSaveDataReadsRevision1Binary(t *testing.T) {
+	data := &SaveData{Version: 1, Entities: []SavedEntity{{ID: newEntityID(0, 1), Components: map[string]map[string]interface{}{}}}}
+	raw, err := data.MarshalBinary()
+	assert.Nil(t, err)
+	// Revision 1 ends each entity after its value list, without the link
+	// counts, name and tag count of later revisions.
+	raw[len(saveMagic)] = 1
+	raw = raw[:len(raw)-4]
+
+	var decoded SaveData
+	assert.Nil(t, decoded.UnmarshalBinary(raw))
+	assert.Equal(t, data.Entities[0].ID, decoded.Entities[0].ID)
+	assert.Empty(t, decoded.Entities[0].Children)
+}
+
+func TestEntitySerializerRestoresTagsAndNames(t *testing.T) {
+	w := NewWorld()
+	boss, minion := w.Spawn(), w.Spawn()
+	boss.SetTag(testHostile)
+	boss.SetTag(testSelected)
+	minion.SetTag(testHostile)
+	assert.Nil(t, w.SetName(boss.ID, "boss"))
+	s := NewEntitySerializer(newSaveFactory(), 1)
+
+	for name, roundTrip := range saveRoundTrips(s) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			restored := NewWorld()
+			assert.Nil(roundTrip(w, restored))
+
+			assert.Equal(boss.Tags(), restored.Get(boss.ID).Tags())
+			assert.Equal(minion.Tags(), restored.Get(minion.ID).Tags())
+			assert.Len(restored.Tagged(testHostile), 2, "Expected restored tags to be indexed")
+			assert.Equal(restored.Get(boss.ID), restored.FindByName("boss"))
+			assert.Equal("", restored.Name(minion.ID))
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package ecs
 
+import (
+	"errors"
+	"time"
+)
+
 // SystemInterface - interface that represents a system, world is an interface and should be cast to whatever data
 // structure the game is currently using or that the system cares about.
 type SystemInterface interface {
@@ -9,12 +14,26 @@ type SystemInterface interface {
 }
 
 // SystemManager - contains a list of systems and is responsible for calling their update functions on entities.
+// Systems run ordered by their SystemOptions (phase, then Before/After constraints, then registration order).
+// When the world data resolves to a *World (see WorldFrom), the world's CommandBuffer is applied after each
+// UpdateSystems, UpdateSystemsForEntities and UpdateSystemsForWorld pass.
 type SystemManager struct {
 	systems            []SystemInterface
 	cachedRequirements [][]ComponentType // Cache Requires() results
+	schedule           Schedule          // Resolves run order from SystemOptions
+	queryWorld         *World            // World the cached queries belong to
+	queries            []*Query          // Cache World.Query results per system
+	profiler           *Profiler         // Optional per-system timing, see SetProfiler
 }
 
+// AddSystem - Registers a system using the options it declares via OptionedSystem, if any.
 func (s *SystemManager) AddSystem(system SystemInterface) {
+	s.AddSystemWithOptions(system, OptionsFor(system))
+}
+
+// AddSystemWithOptions - Registers a system with explicit scheduling options. Ordering errors (cycles, duplicate
+// names) are reported by Resolve and by the Update functions.
+func (s *SystemManager) AddSystemWithOptions(system SystemInterface, opts SystemOptions) {
 	if s.systems == nil {
 		s.systems = make([]SystemInterface, 0)
 		s.cachedRequirements = make([][]ComponentType, 0)
@@ -22,24 +41,76 @@ func (s *SystemManager) AddSystem(system SystemInterface) {
 
 	s.systems = append(s.systems, system)
 	s.cachedRequirements = append(s.cachedRequirements, system.Requires())
+	s.schedule.Add(opts)
+}
+
+// SetEnabled - Enables or disables the system registered under name without removing it. Returns an error if no
+// system has that name.
+func (s *SystemManager) SetEnabled(name string, enabled bool) error {
+	return s.schedule.SetEnabled(name, enabled)
+}
+
+// Enabled - Reports whether the system registered under name is enabled.
+func (s *SystemManager) Enabled(name string) bool {
+	return s.schedule.Enabled(name)
+}
+
+// Resolve - Resolves the run order now, returning any ordering error. Useful to validate registration at startup.
+func (s *SystemManager) Resolve() error {
+	_, err := s.schedule.Order()
+	return err
+}
+
+// SetProfiler - Records per-system timings in p for every update pass. Pass nil to stop profiling.
+func (s *SystemManager) SetProfiler(p *Profiler) {
+	s.profiler = p
+}
+
+// Profiler - Returns the profiler set with SetProfiler, or nil.
+func (s *SystemManager) Profiler() *Profiler {
+	return s.profiler
+}
+
+// record adds a timing sample for system i if profiling is enabled.
+func (s *SystemManager) record(i int, pass Pass, start time.Time, entities int) {
+	if s.profiler != nil {
+		s.profiler.Record(i, SystemName(s.systems[i], s.schedule.Options(i)), pass, time.Since(start), entities)
+	}
 }
 
+// UpdateSystems - Calls UpdateSystem on every system, then applies the world's pending commands.
 func (s *SystemManager) UpdateSystems(world any) error {
-	for system := range s.systems {
-		err := s.systems[system].UpdateSystem(world)
+	return s.flushAfter(world, s.updateSystems(world))
+}
+
+func (s *SystemManager) updateSystems(world any) error {
+	order, err := s.schedule.Runnable(GlobalPass, world)
+	if err != nil {
+		return err
+	}
+	for _, i := range order {
+		start := s.profiler.Start()
+		err := s.systems[i].UpdateSystem(world)
 		if err != nil {
 			return err
 		}
+		s.record(i, GlobalPass, start, 0)
 	}
 	return nil
 }
 
-// UpdateSystemsForEntity - Iterates through the systems for the specific entity
+// UpdateSystemsForEntity - Iterates through the systems for the specific entity. Pending commands are not applied
+// here since the caller is usually iterating entities itself; call FlushCommands after the loop. Disabled systems
+// and RunIf conditions are honoured, but Every is not since there is no pass to count.
 func (s *SystemManager) UpdateSystemsForEntity(world any, entity *Entity) error {
-	for i, system := range s.systems {
+	order, err := s.schedule.Order()
+	if err != nil {
+		return err
+	}
+	for _, i := range order {
 		// Use cached requirements instead of calling Requires() each time
-		if entity.HasComponentsSlice(s.cachedRequirements[i]) {
-			err := system.UpdateEntity(world, entity)
+		if s.schedule.RunsOnce(i, world) && entity.HasComponentsSlice(s.cachedRequirements[i]) {
+			err := s.systems[i].UpdateEntity(world, entity)
 			if err != nil {
 				return err
 			}
@@ -50,22 +121,107 @@ func (s *SystemManager) UpdateSystemsForEntity(world any, entity *Entity) error
 
 // InanimateComponentType can be set by the game to skip inanimate entities.
 // Set to a valid ComponentType to enable the check; leave empty to disable.
+// Entities with the Inanimate tag are skipped either way (see IsInanimate).
 var InanimateComponentType ComponentType = ""
 
+// UpdateSystemsForEntities - Runs each system over the matching entities, then applies the world's pending commands.
 func (s *SystemManager) UpdateSystemsForEntities(world any, entities []*Entity) error {
+	return s.flushAfter(world, s.updateSystemsForEntities(world, entities))
+}
 
-	for i, system := range s.systems {
+func (s *SystemManager) updateSystemsForEntities(world any, entities []*Entity) error {
+	order, err := s.schedule.Runnable(EntityPass, world)
+	if err != nil {
+		return err
+	}
+	for _, i := range order {
+		system := s.systems[i]
 		required := s.cachedRequirements[i]
+		start := s.profiler.Start()
+		processed := 0
 		for _, entity := range entities {
-			if InanimateComponentType != "" && entity.HasComponent(InanimateComponentType) {
+			if IsInanimate(entity) {
 				continue // Skip inanimate entities
 			}
 			if entity.HasComponentsSlice(required) {
 				if err := system.UpdateEntity(world, entity); err != nil {
 					return err
 				}
+				processed++
+			}
+		}
+		s.record(i, EntityPass, start, processed)
+	}
+	return nil
+}
+
+// UpdateSystemsForWorld - Same as UpdateSystemsForEntities, but walks each system's cached World.Query instead of
+// checking every entity against every system's requirements. w's pending commands are applied afterwards.
+func (s *SystemManager) UpdateSystemsForWorld(data any, w *World) error {
+	return joinFlush(s.updateSystemsForWorld(data, w), w.FlushCommands())
+}
+
+func (s *SystemManager) updateSystemsForWorld(data any, w *World) error {
+	order, err := s.schedule.Runnable(EntityPass, data)
+	if err != nil {
+		return err
+	}
+	queries := s.worldQueries(w)
+	for _, i := range order {
+		system := s.systems[i]
+		start := s.profiler.Start()
+		processed := 0
+		err := queries[i].Each(func(entity *Entity) error {
+			if IsInanimate(entity) {
+				return nil // Skip inanimate entities
 			}
+			processed++
+			return system.UpdateEntity(data, entity)
+		})
+		if err != nil {
+			return err
 		}
+		s.record(i, EntityPass, start, processed)
+	}
+	return nil
+}
+
+// FlushCommands - Applies the pending commands of the World that world resolves to, if any.
+func (s *SystemManager) FlushCommands(world any) error {
+	if w, ok := WorldFrom(world); ok {
+		return w.FlushCommands()
 	}
 	return nil
 }
+
+// flushAfter applies pending commands even when the pass failed, so a failing system does not leave commands
+// queued for a later, unrelated pass.
+func (s *SystemManager) flushAfter(world any, err error) error {
+	return joinFlush(err, s.FlushCommands(world))
+}
+
+// joinFlush returns the pass error unchanged when the flush succeeded, so callers can still compare it directly.
+func joinFlush(passErr, flushErr error) error {
+	if flushErr == nil {
+		return passErr
+	}
+	return errors.Join(passErr, flushErr)
+}
+
+func (s *SystemManager) worldQueries(w *World) []*Query {
+	if s.queryWorld != w || len(s.queries) != len(s.systems) {
+		s.queryWorld = w
+		s.queries = WorldQueries(w, s.cachedRequirements)
+	}
+	return s.queries
+}
+
+// WorldQueries - Returns w.Query for each requirement list, in order. Used by system managers to cache the
+// queries for their registered systems.
+func WorldQueries(w *World, requirements [][]ComponentType) []*Query {
+	queries := make([]*Query, len(requirements))
+	for i, required := range requirements {
+		queries[i] = w.Query(required...)
+	}
+	return queries
+}
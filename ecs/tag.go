@@ -0,0 +1,226 @@
+package ecs
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// Tag is a lightweight marker such as "player", "hostile" or "selected",
+// replacing empty marker components. Tags are stored as bits on the entity,
+// so setting, clearing and testing one never allocates.
+//
+// Create tags once, typically as package-level variables, with [NewTag].
+// At most 64 distinct tags exist per program.
+//
+//	var Hostile = ecs.NewTag("hostile")
+//
+//	e.SetTag(Hostile)
+//	for _, enemy := range world.Tagged(Hostile) { ... }
+type Tag uint8
+
+// MaxTags is the number of distinct tags a program can create.
+const MaxTags = 64
+
+var (
+	tagMu    sync.RWMutex
+	tagNames []string
+	tagsByID = map[string]Tag{}
+)
+
+// Inanimate is the built-in tag system managers skip, like entities carrying
+// InanimateComponentType.
+var Inanimate = NewTag("inanimate")
+
+// NewTag returns the tag registered under name, creating it on first use.
+// It panics once MaxTags tags exist.
+func NewTag(name string) Tag {
+	t, err := newTag(name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return t
+}
+
+// newTag is NewTag returning an error once MaxTags tags exist, for tags named
+// by data such as saves.
+func newTag(name string) (Tag, error) {
+	tagMu.Lock()
+	defer tagMu.Unlock()
+	if t, ok := tagsByID[name]; ok {
+		return t, nil
+	}
+	if len(tagNames) >= MaxTags {
+		return 0, fmt.Errorf("ecs: cannot create tag %q: all %d tags are in use", name, MaxTags)
+	}
+	t := Tag(len(tagNames))
+	tagNames = append(tagNames, name)
+	tagsByID[name] = t
+	return t, nil
+}
+
+// TagByName returns the tag created under name, if any.
+func TagByName(name string) (Tag, bool) {
+	tagMu.RLock()
+	defer tagMu.RUnlock()
+	t, ok := tagsByID[name]
+	return t, ok
+}
+
+// String returns the name the tag was created with.
+func (t Tag) String() string {
+	tagMu.RLock()
+	defer tagMu.RUnlock()
+	if int(t) < len(tagNames) {
+		return tagNames[t]
+	}
+	return fmt.Sprintf("Tag(%d)", t)
+}
+
+// TagSet is a set of tags.
+type TagSet uint64
+
+// Has reports whether t is in the set.
+func (s TagSet) Has(t Tag) bool {
+	return s&(1<<t) != 0
+}
+
+// Len returns the number of tags in the set.
+func (s TagSet) Len() int {
+	return bits.OnesCount64(uint64(s))
+}
+
+// Tags returns the tags in the set in creation order.
+func (s TagSet) Tags() []Tag {
+	tags := make([]Tag, 0, s.Len())
+	for rest := uint64(s); rest != 0; rest &= rest - 1 {
+		tags = append(tags, Tag(bits.TrailingZeros64(rest)))
+	}
+	return tags
+}
+
+// SetTag - Adds t to the entity's tags. While the entity's world defers component changes, the tag is
+// recorded and set when they are applied.
+func (entity *Entity) SetTag(t Tag) {
+	if entity.world != nil && entity.world.deferring != deferNone {
+		entity.world.deferred.SetTag(entity, t)
+		return
+	}
+	if entity.tags.Has(t) {
+		return
+	}
+	entity.tags |= 1 << t
+	if entity.world != nil {
+		entity.world.tagIndex(t).add(entity)
+	}
+}
+
+// ClearTag - Removes t from the entity's tags. While the entity's world defers component changes, the
+// tag is recorded and cleared when they are applied.
+func (entity *Entity) ClearTag(t Tag) {
+	if entity.world != nil && entity.world.deferring != deferNone {
+		entity.world.deferred.ClearTag(entity, t)
+		return
+	}
+	if !entity.tags.Has(t) {
+		return
+	}
+	entity.tags &^= 1 << t
+	if entity.world != nil {
+		entity.world.tagIndex(t).remove(entity)
+	}
+}
+
+// HasTag - Returns if the entity has tag t.
+func (entity *Entity) HasTag(t Tag) bool {
+	return entity.tags.Has(t)
+}
+
+// Tags - Returns the entity's tags.
+func (entity *Entity) Tags() TagSet {
+	return entity.tags
+}
+
+// IsInanimate reports whether system managers skip e: it has the Inanimate
+// tag, or a component of InanimateComponentType when that is set.
+func IsInanimate(e *Entity) bool {
+	return e.tags.Has(Inanimate) || (InanimateComponentType != "" && e.HasComponent(InanimateComponentType))
+}
+
+// Tagged returns the live entities with tag t. The slice is owned by the
+// World and is only valid until the next tag or structural change; do not
+// modify it.
+func (w *World) Tagged(t Tag) []*Entity {
+	if q := w.tagged[t]; q != nil {
+		return q.entities
+	}
+	return nil
+}
+
+// tagIndex returns the entity set for t, creating it on first use.
+func (w *World) tagIndex(t Tag) *Query {
+	if w.tagged[t] == nil {
+		w.tagged[t] = &Query{index: make(map[EntityID]int)}
+	}
+	return w.tagged[t]
+}
+
+// indexTags adds a newly attached entity to the index of each of its tags.
+func (w *World) indexTags(e *Entity) {
+	for _, t := range e.tags.Tags() {
+		w.tagIndex(t).add(e)
+	}
+}
+
+// unindexTags removes a despawning entity from the tag index and drops its name.
+func (w *World) unindexTags(e *Entity) {
+	for _, t := range e.tags.Tags() {
+		w.tagged[t].remove(e)
+	}
+	if name, ok := w.entityNames[e.ID]; ok {
+		delete(w.names, name)
+		delete(w.entityNames, e.ID)
+	}
+}
+
+// SetName gives the entity a name unique within the world, replacing any
+// previous name; an empty name clears it. Returns an error if the entity is
+// not alive or another entity already has the name.
+func (w *World) SetName(id EntityID, name string) error {
+	if !w.Alive(id) {
+		return fmt.Errorf("ecs: name %s %q: %w", id, name, ErrDeadEntity)
+	}
+	if owner, ok := w.names[name]; ok && name != "" {
+		if owner == id {
+			return nil
+		}
+		return fmt.Errorf("ecs: name %q is already used by %s", name, owner)
+	}
+	if old, ok := w.entityNames[id]; ok {
+		delete(w.names, old)
+		delete(w.entityNames, id)
+	}
+	if name == "" {
+		return nil
+	}
+	if w.names == nil {
+		w.names = make(map[string]EntityID)
+		w.entityNames = make(map[EntityID]string)
+	}
+	w.names[name] = id
+	w.entityNames[id] = name
+	return nil
+}
+
+// Name returns the entity's name, or "" if it has none.
+func (w *World) Name(id EntityID) string {
+	return w.entityNames[id]
+}
+
+// FindByName returns the live entity with the given name, or nil.
+func (w *World) FindByName(name string) *Entity {
+	if id, ok := w.names[name]; ok {
+		return w.Get(id)
+	}
+	return nil
+}
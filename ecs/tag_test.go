@@ -0,0 +1,122 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testHostile  = NewTag("test-hostile")
+	testSelected = NewTag("test-selected")
+)
+
+// countingSystem counts the entities it is run for.
+type countingSystem struct{ entities int }
+
+func (s *countingSystem) UpdateSystem(data any) error { return nil }
+
+func (s *countingSystem) UpdateEntity(data any, entity *Entity) error {
+	s.entities++
+	return nil
+}
+
+func (s *countingSystem) Requires() []ComponentType { return []ComponentType{testComponentType} }
+
+func TestNewTagIsIdempotent(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(testHostile, NewTag("test-hostile"))
+	assert.NotEqual(testHostile, testSelected)
+	assert.Equal("test-hostile", testHostile.String())
+
+	tag, ok := TagByName("test-selected")
+	assert.True(ok)
+	assert.Equal(testSelected, tag)
+	_, ok = TagByName("never-created")
+	assert.False(ok)
+}
+
+func TestEntityTagsAndIndex(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	a := w.Spawn()
+	b := w.Spawn()
+	a.SetTag(testHostile)
+	a.SetTag(testHostile)
+	b.SetTag(testHostile)
+	b.SetTag(testSelected)
+
+	assert.True(a.HasTag(testHostile))
+	assert.False(a.HasTag(testSelected))
+	assert.Equal(2, b.Tags().Len())
+	assert.ElementsMatch([]*Entity{a, b}, w.Tagged(testHostile))
+	assert.Equal([]*Entity{b}, w.Tagged(testSelected))
+
+	b.ClearTag(testHostile)
+	assert.Equal([]*Entity{a}, w.Tagged(testHostile))
+
+	w.Despawn(a.ID)
+	assert.Empty(w.Tagged(testHostile), "Expected despawned entities to leave the tag index")
+
+	// Tags set before the entity joins a world are indexed on Add.
+	c := &Entity{}
+	c.SetTag(testHostile)
+	_, err := w.Add(c)
+	assert.NoError(err)
+	assert.Equal([]*Entity{c}, w.Tagged(testHostile))
+}
+
+func TestSetTagDoesNotAllocate(t *testing.T) {
+	w := NewWorld()
+	e := w.Spawn()
+	e.SetTag(testSelected)
+	e.ClearTag(testSelected)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		e.SetTag(testSelected)
+		_ = e.HasTag(testSelected)
+		e.ClearTag(testSelected)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestEntityNames(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	player := w.Spawn()
+	other := w.Spawn()
+
+	assert.NoError(w.SetName(player.ID, "player"))
+	assert.Same(player, w.FindByName("player"))
+	assert.Equal("player", w.Name(player.ID))
+	assert.Error(w.SetName(other.ID, "player"), "Expected names to be unique")
+
+	assert.NoError(w.SetName(player.ID, "hero"))
+	assert.Nil(w.FindByName("player"), "Expected renaming to release the old name")
+	assert.NoError(w.SetName(other.ID, "player"))
+
+	w.Despawn(player.ID)
+	assert.Nil(w.FindByName("hero"))
+	assert.Empty(w.Name(player.ID))
+	assert.ErrorIs(w.SetName(player.ID, "ghost"), ErrDeadEntity)
+}
+
+func TestManagerSkipsInanimateTag(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	moving := w.Spawn()
+	moving.AddComponent(TestComponent{})
+	still := w.Spawn()
+	still.AddComponent(TestComponent{})
+	still.SetTag(Inanimate)
+
+	assert.True(IsInanimate(still))
+	assert.False(IsInanimate(moving))
+
+	sys := &countingSystem{}
+	m := &SystemManager{}
+	m.AddSystem(sys)
+	assert.NoError(m.UpdateSystemsForEntities(w, w.Entities()))
+	assert.Equal(1, sys.entities)
+}
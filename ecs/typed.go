@@ -0,0 +1,72 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// componentTypes caches the ComponentType reported by each Go component type.
+var componentTypes sync.Map // reflect.Type -> ComponentType
+
+// TypeOf returns the ComponentType that components of Go type T register
+// under, i.e. the result of T's GetType method. The result is cached per type.
+//
+// T may be a value type (EmitterComponent) or a pointer type (*HealthComponent);
+// use whichever form the component is stored as.
+func TypeOf[T Component]() ComponentType {
+	rt := reflect.TypeFor[T]()
+	if ct, ok := componentTypes.Load(rt); ok {
+		return ct.(ComponentType)
+	}
+	var zero T
+	if rt.Kind() == reflect.Pointer {
+		// Use a non-nil instance so value-receiver GetType methods are safe.
+		zero = reflect.New(rt.Elem()).Interface().(T)
+	}
+	ct := zero.GetType()
+	componentTypes.Store(rt, ct)
+	return ct
+}
+
+// Has reports whether e has a component stored as type T.
+func Has[T Component](e *Entity) bool {
+	_, ok := TryGet[T](e)
+	return ok
+}
+
+// Get returns e's component of type T, or the zero T if it is missing.
+//
+//	pos := ecs.Get[basecomponents.Position2dComponent](e)
+func Get[T Component](e *Entity) T {
+	v, _ := TryGet[T](e)
+	return v
+}
+
+// TryGet returns e's component of type T and whether it was present.
+// It also returns false if a component is registered under T's ComponentType
+// but is stored as a different Go type (e.g. a value where T is a pointer).
+func TryGet[T Component](e *Entity) (T, bool) {
+	c, ok := e.Components[TypeOf[T]()]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	v, ok := c.(T)
+	return v, ok
+}
+
+// Mutate calls fn with a pointer to e's component of type T and writes the
+// result back to the entity, so value-type components can be edited in place:
+//
+//	ecs.Mutate(e, func(c *particle.EmitterComponent) { c.BurstCount = 0 })
+//
+// Returns false, without calling fn, if e has no component of type T.
+func Mutate[T Component](e *Entity, fn func(*T)) bool {
+	v, ok := TryGet[T](e)
+	if !ok {
+		return false
+	}
+	fn(&v)
+	e.AddComponent(v)
+	return true
+}
@@ -0,0 +1,64 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type counterComponent struct {
+	Count int
+}
+
+var counterComponentType ComponentType = "CounterComponent"
+
+func (c counterComponent) GetType() ComponentType {
+	return counterComponentType
+}
+
+type healthComponent struct {
+	Health int
+}
+
+var healthComponentType ComponentType = "HealthComponent"
+
+func (c *healthComponent) GetType() ComponentType {
+	return healthComponentType
+}
+
+func TestTypeOf(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(counterComponentType, TypeOf[counterComponent]())
+	assert.Equal(counterComponentType, TypeOf[*counterComponent](), "Expected value-receiver GetType to work through a pointer")
+	assert.Equal(healthComponentType, TypeOf[*healthComponent]())
+}
+
+func TestGetAndTryGet(t *testing.T) {
+	assert := assert.New(t)
+	e := &Entity{}
+	e.AddComponent(counterComponent{Count: 3})
+	e.AddComponent(&healthComponent{Health: 10})
+
+	assert.Equal(3, Get[counterComponent](e).Count)
+	assert.Equal(10, Get[*healthComponent](e).Health)
+	assert.True(Has[counterComponent](e))
+
+	_, ok := TryGet[TestComponent](e)
+	assert.False(ok, "Expected missing component to report false")
+
+	_, ok = TryGet[*counterComponent](e)
+	assert.False(ok, "Expected stored value type not to match pointer type")
+}
+
+func TestMutateWritesBackValueComponents(t *testing.T) {
+	assert := assert.New(t)
+	e := &Entity{}
+	e.AddComponent(counterComponent{Count: 1})
+
+	ok := Mutate(e, func(c *counterComponent) { c.Count++ })
+
+	assert.True(ok)
+	assert.Equal(2, Get[counterComponent](e).Count)
+	assert.False(Mutate(e, func(c *TestComponent) {}), "Expected Mutate on a missing component to report false")
+}
@@ -0,0 +1,312 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EntityID is a stable, generational identifier for an entity owned by a [World].
+//
+// The low 32 bits hold the slot index and the high 32 bits hold the slot's
+// generation. When an entity is despawned its slot generation is bumped, so an
+// old ID never resolves to the entity that later reuses the slot.
+//
+// The zero value is never issued and can be used as "no entity".
+type EntityID uint64
+
+// NoEntity is the zero EntityID. It never refers to a live entity.
+const NoEntity EntityID = 0
+
+func newEntityID(index, generation uint32) EntityID {
+	return EntityID(uint64(generation)<<32 | uint64(index))
+}
+
+// Index returns the slot index portion of the ID.
+func (id EntityID) Index() uint32 {
+	return uint32(id)
+}
+
+// Generation returns the generation portion of the ID.
+func (id EntityID) Generation() uint32 {
+	return uint32(id >> 32)
+}
+
+// String formats the ID as "index.generation", e.g. "12.3".
+// Use [ParseEntityID] to convert it back.
+func (id EntityID) String() string {
+	return strconv.FormatUint(uint64(id.Index()), 10) + "." + strconv.FormatUint(uint64(id.Generation()), 10)
+}
+
+// ParseEntityID parses an ID produced by [EntityID.String].
+func ParseEntityID(s string) (EntityID, error) {
+	idx, gen, ok := strings.Cut(s, ".")
+	if !ok {
+		return NoEntity, fmt.Errorf("invalid entity id %q", s)
+	}
+	i, err := strconv.ParseUint(idx, 10, 32)
+	if err != nil {
+		return NoEntity, fmt.Errorf("invalid entity id %q: %w", s, err)
+	}
+	g, err := strconv.ParseUint(gen, 10, 32)
+	if err != nil {
+		return NoEntity, fmt.Errorf("invalid entity id %q: %w", s, err)
+	}
+	return newEntityID(uint32(i), uint32(g)), nil
+}
+
+// MarshalText implements encoding.TextMarshaler, so IDs stored in components
+// are written to JSON as "index.generation" strings without precision loss.
+func (id EntityID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *EntityID) UnmarshalText(text []byte) error {
+	parsed, err := ParseEntityID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// ErrEntityOwned is returned when adding an entity that already belongs to a world.
+var ErrEntityOwned = errors.New("entity already belongs to a world")
+
+// World owns the live entity set and allocates generational [EntityID]s.
+//
+// A *World can be passed anywhere a `world any` is expected (SystemManager,
+// simulation.Server, client.Client). Game context structs that want to carry
+// extra state can embed *World, or implement [WorldProvider], so that generic
+// code can still find it with [WorldFrom].
+//
+// Create with [NewWorld].
+type World struct {
+	slots       []*Entity // indexed by EntityID.Index(); nil when the slot is free
+	generations []uint32  // current generation of each slot
+	denseIndex  []int     // position of each slot's entity in entities
+	free        []uint32  // free slot indices, reused LIFO
+	entities    []*Entity // dense list of live entities
+
+	queries       map[string]*Query
+	queriesByType map[ComponentType][]*Query
+
+	commands CommandBuffer // structural changes deferred to the next sync point
+
+	deferring deferMode     // see DeferComponentChanges
+	deferred  CommandBuffer // component changes made while deferring
+
+	observers    map[ComponentType]*componentObservers
+	lastObserver ObserverHandle
+
+	parents   map[EntityID]EntityID
+	children  map[EntityID][]EntityID
+	relations map[Relation]*relationIndex
+
+	tagged      [MaxTags]*Query // entities per tag, created on first use
+	names       map[string]EntityID
+	entityNames map[EntityID]string
+
+	pool *EntityPool // receives despawned entities when set
+
+	resourceMu sync.RWMutex
+	resources  map[reflect.Type]any // singleton resources keyed by Go type
+}
+
+// NewWorld creates an empty World.
+func NewWorld() *World {
+	return &World{}
+}
+
+// Spawn creates a new empty entity, assigns it an ID and adds it to the world.
+func (w *World) Spawn() *Entity {
+	e := &Entity{}
+	w.attach(w.allocate(), e)
+	return e
+}
+
+// Add adopts an entity built elsewhere (e.g. by [JSONFactory.Create]) and
+// assigns it a new ID.
+func (w *World) Add(e *Entity) (EntityID, error) {
+	if e.world != nil {
+		return NoEntity, ErrEntityOwned
+	}
+	id := w.allocate()
+	w.attach(id, e)
+	return id, nil
+}
+
+// AddWithID adopts an entity under a specific ID, for example one received in
+// a network snapshot or read from a save file. It fails if the slot is
+// occupied by a live entity, or if id's generation is older than the slot's,
+// which would bring a despawned ID back to life; see Forget.
+func (w *World) AddWithID(id EntityID, e *Entity) error {
+	if id == NoEntity {
+		return errors.New("cannot add entity with NoEntity id")
+	}
+	if e.world != nil {
+		return ErrEntityOwned
+	}
+	index := id.Index()
+	for uint32(len(w.slots)) <= index {
+		// Grow the slot table; intermediate slots become free.
+		w.free = append(w.free, uint32(len(w.slots)))
+		w.slots = append(w.slots, nil)
+		w.generations = append(w.generations, 1)
+		w.denseIndex = append(w.denseIndex, -1)
+	}
+	if w.slots[index] != nil {
+		return fmt.Errorf("entity slot %d is occupied by %s", index, w.slots[index].ID)
+	}
+	if id.Generation() < w.generations[index] {
+		return fmt.Errorf("entity %s is stale: slot %d is at generation %d", id, index, w.generations[index])
+	}
+	for i, f := range w.free {
+		if f == index {
+			w.free = append(w.free[:i], w.free[i+1:]...)
+			break
+		}
+	}
+	w.generations[index] = id.Generation()
+	w.attach(id, e)
+	return nil
+}
+
+// Despawn removes the entity with the given ID from the world, after
+// despawning its children and dropping its relation links.
+// Returns false if the ID does not refer to a live entity.
+func (w *World) Despawn(id EntityID) bool {
+	return w.despawn(id, false)
+}
+
+// Forget is Despawn for worlds that mirror another world's IDs, such as a
+// client dropping an entity that left its interest: the slot keeps its
+// generation, so AddWithID accepts the same ID when the entity comes back.
+// Children are forgotten too. A world that allocates its own IDs should use
+// Despawn, so stale IDs stay dead.
+func (w *World) Forget(id EntityID) bool {
+	return w.despawn(id, true)
+}
+
+func (w *World) despawn(id EntityID, keepGeneration bool) bool {
+	e := w.Get(id)
+	if e == nil {
+		return false
+	}
+	w.unlinkEntity(id, keepGeneration)
+	index := id.Index()
+	for t, c := range e.Components {
+		w.notifyRemoved(e, t, c)
+	}
+	w.unindexEntity(e)
+	w.unindexTags(e)
+
+	// Swap-remove from the dense list.
+	pos := w.denseIndex[index]
+	last := len(w.entities) - 1
+	moved := w.entities[last]
+	w.entities[pos] = moved
+	w.denseIndex[moved.ID.Index()] = pos
+	w.entities[last] = nil // clear trailing slot so GC can reclaim the entity
+	w.entities = w.entities[:last]
+
+	w.slots[index] = nil
+	w.denseIndex[index] = -1
+	if !keepGeneration {
+		w.generations[index]++
+		if w.generations[index] == 0 {
+			w.generations[index] = 1
+		}
+	}
+	w.free = append(w.free, index)
+
+	e.world = nil
+	if w.pool != nil && e.pool == w.pool {
+		w.pool.Release(e)
+	}
+	return true
+}
+
+// Get returns the live entity for id, or nil if it has been despawned or never existed.
+func (w *World) Get(id EntityID) *Entity {
+	index := id.Index()
+	if id == NoEntity || index >= uint32(len(w.slots)) {
+		return nil
+	}
+	e := w.slots[index]
+	if e == nil || w.generations[index] != id.Generation() {
+		return nil
+	}
+	return e
+}
+
+// Alive reports whether id refers to a live entity.
+func (w *World) Alive(id EntityID) bool {
+	return w.Get(id) != nil
+}
+
+// Entities returns the live entities. The returned slice is owned by the
+// world and is only valid until the next Spawn/Add/Despawn; do not modify it.
+//
+// The method value w.Entities satisfies simulation.EntitySource.
+func (w *World) Entities() []*Entity {
+	return w.entities
+}
+
+// Len returns the number of live entities.
+func (w *World) Len() int {
+	return len(w.entities)
+}
+
+// ECSWorld satisfies [WorldProvider].
+func (w *World) ECSWorld() *World {
+	return w
+}
+
+func (w *World) allocate() EntityID {
+	if n := len(w.free); n > 0 {
+		index := w.free[n-1]
+		w.free = w.free[:n-1]
+		return newEntityID(index, w.generations[index])
+	}
+	index := uint32(len(w.slots))
+	w.slots = append(w.slots, nil)
+	w.generations = append(w.generations, 1)
+	w.denseIndex = append(w.denseIndex, -1)
+	return newEntityID(index, 1)
+}
+
+func (w *World) attach(id EntityID, e *Entity) {
+	e.ID = id
+	e.world = w
+	index := id.Index()
+	w.slots[index] = e
+	w.denseIndex[index] = len(w.entities)
+	w.entities = append(w.entities, e)
+	w.indexEntity(e)
+	w.indexTags(e)
+	for t, c := range e.Components {
+		w.notifyAdded(e, t, c)
+	}
+}
+
+// WorldProvider is implemented by game world structs that own an *ecs.World.
+// Embedding *World satisfies it automatically.
+type WorldProvider interface {
+	ECSWorld() *World
+}
+
+// WorldFrom extracts the *World from the `world any` value systems receive.
+// It returns false if data neither is nor provides a *World.
+func WorldFrom(data any) (*World, bool) {
+	if p, ok := data.(WorldProvider); ok {
+		if w := p.ECSWorld(); w != nil {
+			return w, true
+		}
+	}
+	return nil, false
+}
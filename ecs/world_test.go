@@ -0,0 +1,141 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorldSpawnAssignsIDs(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	a := w.Spawn()
+	b := w.Spawn()
+
+	assert.NotEqual(NoEntity, a.ID, "Expected spawned entity to have an ID")
+	assert.NotEqual(a.ID, b.ID, "Expected distinct IDs")
+	assert.Equal(w, a.World(), "Expected entity to reference its world")
+	assert.Equal(2, w.Len())
+	assert.Equal(a, w.Get(a.ID))
+}
+
+func TestWorldDespawnInvalidatesID(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	a := w.Spawn()
+	oldID := a.ID
+	assert.True(w.Despawn(oldID))
+	assert.False(w.Alive(oldID), "Expected despawned ID to be dead")
+	assert.Nil(a.World(), "Expected despawned entity to be detached")
+	assert.False(w.Despawn(oldID), "Expected double despawn to fail")
+
+	b := w.Spawn()
+	assert.Equal(oldID.Index(), b.ID.Index(), "Expected slot to be reused")
+	assert.NotEqual(oldID, b.ID, "Expected generation to differ on reuse")
+	assert.Nil(w.Get(oldID), "Expected stale ID not to resolve to the new entity")
+}
+
+func TestWorldDespawnKeepsDenseListConsistent(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	ids := []EntityID{}
+	for i := 0; i < 5; i++ {
+		ids = append(ids, w.Spawn().ID)
+	}
+	w.Despawn(ids[1])
+	w.Despawn(ids[3])
+
+	assert.Equal(3, w.Len())
+	for _, e := range w.Entities() {
+		assert.Equal(e, w.Get(e.ID))
+	}
+	assert.True(w.Despawn(ids[4]), "Expected moved entity to still be despawnable")
+	assert.Equal(2, w.Len())
+}
+
+func TestWorldAdd(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	e := &Entity{Blueprint: "test"}
+	e.AddComponent(TestComponent{})
+
+	id, err := w.Add(e)
+	assert.Nil(err)
+	assert.Equal(id, e.ID)
+	assert.Equal(e, w.Get(id))
+
+	_, err = NewWorld().Add(e)
+	assert.ErrorIs(err, ErrEntityOwned)
+}
+
+func TestWorldAddWithID(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	id := newEntityID(4, 7)
+
+	e := &Entity{}
+	assert.Nil(w.AddWithID(id, e))
+	assert.Equal(e, w.Get(id))
+	assert.Error(w.AddWithID(id, &Entity{}), "Expected occupied slot to fail")
+
+	// Skipped slots are available to Spawn and never collide with id.
+	for i := 0; i < 4; i++ {
+		assert.NotEqual(id.Index(), w.Spawn().ID.Index())
+	}
+	assert.Equal(5, w.Len())
+
+	w.Despawn(id)
+	assert.Error(w.AddWithID(id, &Entity{}), "Expected a despawned ID to stay dead")
+	assert.Error(w.AddWithID(newEntityID(4, 2), &Entity{}), "Expected older generations to be rejected")
+	assert.Nil(w.AddWithID(newEntityID(4, 9), &Entity{}), "Expected newer generations to be accepted")
+}
+
+func TestWorldForgetKeepsIDsReusable(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+	parent, child := newEntityID(0, 3), newEntityID(1, 5)
+	assert.Nil(w.AddWithID(parent, &Entity{}))
+	assert.Nil(w.AddWithID(child, &Entity{}))
+	assert.Nil(w.SetParent(child, parent))
+
+	assert.True(w.Forget(parent))
+	assert.False(w.Alive(child), "Expected children to be forgotten with their parent")
+	assert.Nil(w.AddWithID(parent, &Entity{}), "Expected a forgotten ID to be accepted again")
+	assert.Nil(w.AddWithID(child, &Entity{}))
+}
+
+func TestEntityIDStringRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	id := newEntityID(12, 3)
+
+	assert.Equal("12.3", id.String())
+	parsed, err := ParseEntityID(id.String())
+	assert.Nil(err)
+	assert.Equal(id, parsed)
+
+	_, err = ParseEntityID("nope")
+	assert.Error(err)
+}
+
+type worldHolder struct {
+	*World
+}
+
+func TestWorldFrom(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWorld()
+
+	got, ok := WorldFrom(w)
+	assert.True(ok)
+	assert.Equal(w, got)
+
+	got, ok = WorldFrom(&worldHolder{World: w})
+	assert.True(ok, "Expected embedded world to be found")
+	assert.Equal(w, got)
+
+	_, ok = WorldFrom("not a world")
+	assert.False(ok)
+}
@@ -13,7 +13,8 @@ import (
 // example.
 //
 // Encode is identical to the local example: build a Snapshot from live server
-// entities, omitting VelocityComponent (server-private).
+// entities, omitting VelocityComponent (server-private). Entity IDs come from
+// the server's ecs.World, so no ID component is needed.
 //
 // Decode must handle the TCP case: component values arrive over JSON as
 // map[string]interface{} rather than typed structs (because transport.ComponentData
@@ -28,10 +29,6 @@ var _ transport.SnapshotCodec = (*BallCodec)(nil)
 func (c *BallCodec) Encode(tick uint64, entities []*ecs.Entity) *transport.Snapshot {
 	snaps := make([]*transport.EntitySnapshot, 0, len(entities))
 	for _, e := range entities {
-		idC, ok := e.Components[TypeID]
-		if !ok {
-			continue
-		}
 		posC, ok := e.Components[TypePosition]
 		if !ok {
 			continue
@@ -41,7 +38,7 @@ func (c *BallCodec) Encode(tick uint64, entities []*ecs.Entity) *transport.Snaps
 			continue
 		}
 		snaps = append(snaps, &transport.EntitySnapshot{
-			ID:        idC.(IDComponent).ID,
+			ID:        e.ID.String(),
 			Blueprint: e.Blueprint,
 			Components: map[ecs.ComponentType]transport.ComponentData{
 				TypePosition: posC.(PositionComponent),
@@ -65,11 +62,20 @@ func (c *BallCodec) Decode(snap *transport.Snapshot, world any) {
 	w := world.(*World)
 
 	// Build a set of IDs present in this snapshot.
-	seen := make(map[string]bool, len(snap.Entities))
+	seen := make(map[ecs.EntityID]bool, len(snap.Entities))
 
 	for _, es := range snap.Entities {
-		seen[es.ID] = true
-		e := w.FindOrCreateEntity(es.ID)
+		id, err := ecs.ParseEntityID(es.ID)
+		if err != nil {
+			log.Printf("codec: %v", err)
+			continue
+		}
+		seen[id] = true
+		e, err := w.FindOrCreateEntity(id)
+		if err != nil {
+			log.Printf("codec: %v", err)
+			continue
+		}
 
 		if raw, ok := es.Components[TypePosition]; ok {
 			var pos PositionComponent
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"image/color"
 
 	"github.com/mechanical-lich/mlge/ecs"
@@ -10,18 +9,11 @@ import (
 // ---- Component types --------------------------------------------------------
 
 const (
-	TypeID       ecs.ComponentType = "ID"
 	TypePosition ecs.ComponentType = "Position"
 	TypeVelocity ecs.ComponentType = "Velocity"
 	TypeColor    ecs.ComponentType = "Color"
 )
 
-// IDComponent assigns a stable string identity to an entity so the snapshot
-// codec can correlate server entities with client-side counterparts.
-type IDComponent struct{ ID string }
-
-func (c IDComponent) GetType() ecs.ComponentType { return TypeID }
-
 // PositionComponent stores the entity's world-space position.
 // Both server and client carry this component; the server is authoritative.
 type PositionComponent struct{ X, Y float64 }
@@ -43,8 +35,6 @@ func (c ColorComponent) GetType() ecs.ComponentType { return TypeColor }
 
 // ---- Helpers -----------------------------------------------------------------
 
-func ballID(i int) string { return fmt.Sprintf("ball-%d", i) }
-
 var palette = []color.RGBA{
 	{220, 60, 60, 255},
 	{60, 180, 60, 255},
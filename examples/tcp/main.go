@@ -30,7 +30,6 @@ import (
 	"syscall"
 
 	"github.com/mechanical-lich/mlge/client"
-	"github.com/mechanical-lich/mlge/ecs"
 	"github.com/mechanical-lich/mlge/simulation"
 	"github.com/mechanical-lich/mlge/transport"
 )
@@ -67,10 +66,10 @@ func runServer(addr string) {
 	defer srvT.Close()
 	log.Printf("server: listening on %s", addr)
 
-	world := &World{Width: screenW, Height: screenH}
+	world := NewWorld(screenW, screenH)
 	for i := range ballCount {
-		e := &ecs.Entity{Blueprint: "ball"}
-		e.AddComponent(IDComponent{ID: ballID(i)})
+		e := world.Spawn()
+		e.Blueprint = "ball"
 		e.AddComponent(PositionComponent{
 			X: rand.Float64() * screenW,
 			Y: rand.Float64() * screenH,
@@ -80,14 +79,13 @@ func runServer(addr string) {
 			VY: (rand.Float64()*2 - 1) * 120,
 		})
 		e.AddComponent(ColorComponent{ballColor(i)})
-		world.Entities = append(world.Entities, e)
 	}
 
 	codec := &BallCodec{}
 	srv := simulation.NewServer(
 		simulation.ServerConfig{TickRate: tickRate, SnapshotEvery: 1},
 		world,
-		func() []*ecs.Entity { return world.Entities },
+		world.Entities,
 		srvT,
 		codec,
 	)
@@ -114,7 +112,7 @@ func runClient(addr string) {
 	defer cliT.Close()
 	log.Printf("client: connected to %s", addr)
 
-	cliWorld := &World{Width: screenW, Height: screenH}
+	cliWorld := NewWorld(screenW, screenH)
 	codec := &BallCodec{}
 
 	c := client.NewClient(
@@ -122,7 +120,7 @@ func runClient(addr string) {
 		codec,
 		&MainClientState{world: cliWorld},
 		cliWorld,
-		func() []*ecs.Entity { return cliWorld.Entities },
+		cliWorld.Entities,
 		client.ClientConfig{
 			ScreenWidth:  screenW,
 			ScreenHeight: screenH,
@@ -47,7 +47,7 @@ func (s *MainClientState) Update(snapshot *transport.Snapshot) client.ClientStat
 func (s *MainClientState) Draw(screen *ebiten.Image) {
 	screen.Fill(colorBG)
 
-	for _, e := range s.world.Entities {
+	for _, e := range s.world.Entities() {
 		posC, hasPos := e.Components[TypePosition]
 		colC, hasCol := e.Components[TypeColor]
 		if !hasPos || !hasCol {
@@ -67,6 +67,6 @@ func (s *MainClientState) Draw(screen *ebiten.Image) {
 
 	ebitenutil.DebugPrint(screen, fmt.Sprintf(
 		"TCP MODE\nserver tick: %d\nballs: %d\nFPS: %.0f",
-		s.latestTick, len(s.world.Entities), ebiten.ActualFPS(),
+		s.latestTick, s.world.Len(), ebiten.ActualFPS(),
 	))
 }
@@ -7,57 +7,44 @@ const ballRadius = 10.0
 // World is the shared world type for this example.
 // Both the server and the client hold an instance; they are separate values.
 // The server world is authoritative; the client world is updated from snapshots.
+//
+// It embeds *ecs.World, which owns the entities and hands out the stable IDs
+// the codec puts on the wire.
 type World struct {
+	*ecs.World
 	Width, Height float64
-	Entities      []*ecs.Entity
+}
 
-	// entityByID caches client-side entities for O(1) snapshot decode lookups.
-	entityByID map[string]*ecs.Entity
+// NewWorld creates an empty World of the given size.
+func NewWorld(width, height float64) *World {
+	return &World{World: ecs.NewWorld(), Width: width, Height: height}
 }
 
-// RemoveAbsent removes any entities whose IDs are not in the keep set.
-// Called by the codec after each snapshot decode to clean up destroyed entities.
-func (w *World) RemoveAbsent(keep map[string]bool) {
-	if w.entityByID == nil {
-		return
-	}
-	j := 0
-	for _, e := range w.Entities {
-		var id string
-		if c, ok := e.Components[TypeID]; ok {
-			id = c.(IDComponent).ID
-		}
-		if keep[id] {
-			w.Entities[j] = e
-			j++
-		} else {
-			delete(w.entityByID, id)
+// RemoveAbsent forgets any entities whose IDs are not in the keep set, so
+// the server's IDs stay valid here. Called by the codec after each snapshot
+// decode to clean up destroyed entities.
+func (w *World) RemoveAbsent(keep map[ecs.EntityID]bool) {
+	var drop []ecs.EntityID
+	for _, e := range w.Entities() {
+		if !keep[e.ID] {
+			drop = append(drop, e.ID)
 		}
 	}
-	// Nil out trailing slots so the GC can reclaim the dropped *ecs.Entity values.
-	for k := j; k < len(w.Entities); k++ {
-		w.Entities[k] = nil
+	for _, id := range drop {
+		w.Forget(id)
 	}
-	w.Entities = w.Entities[:j]
 }
 
-// FindOrCreateEntity returns the entity with the given ID, creating one if it
-// does not yet exist. Used by the codec during snapshot decode.
-func (w *World) FindOrCreateEntity(id string) *ecs.Entity {
-	if w.entityByID == nil {
-		w.entityByID = make(map[string]*ecs.Entity)
-		for _, e := range w.Entities {
-			if c, ok := e.Components[TypeID]; ok {
-				w.entityByID[c.(IDComponent).ID] = e
-			}
-		}
-	}
-	if e, ok := w.entityByID[id]; ok {
-		return e
+// FindOrCreateEntity returns the entity with the given server ID, creating one
+// under that same ID if it does not yet exist. Used by the codec during
+// snapshot decode.
+func (w *World) FindOrCreateEntity(id ecs.EntityID) (*ecs.Entity, error) {
+	if e := w.Get(id); e != nil {
+		return e, nil
 	}
 	e := &ecs.Entity{Blueprint: "ball"}
-	e.AddComponent(IDComponent{ID: id})
-	w.Entities = append(w.Entities, e)
-	w.entityByID[id] = e
-	return e
+	if err := w.AddWithID(id, e); err != nil {
+		return nil, err
+	}
+	return e, nil
 }
@@ -39,6 +39,9 @@ type ParticleSystem struct {
 	// DT is the fixed timestep in seconds used to advance particle simulation.
 	//   - Render-side: set to 1.0/60.0 or update each frame.
 	//   - Simulation-side: set to 1.0/tickRate.
+	//
+	// If the world passed to the update methods carries an [ecs.DeltaTime]
+	// resource, that is used instead.
 	DT float64
 
 	// pools holds the live particle slice for each emitter entity.
@@ -74,7 +77,9 @@ func (ps *ParticleSystem) Requires() []ecs.ComponentType {
 func (ps *ParticleSystem) UpdateSystem(_ any) error { return nil }
 
 // UpdateEntity advances the particle emitter for a single entity.
-func (ps *ParticleSystem) UpdateEntity(_ any, e *ecs.Entity) error { return ps.advance(e) }
+func (ps *ParticleSystem) UpdateEntity(world any, e *ecs.Entity) error {
+	return ps.advanceBy(e, ps.timestep(world))
+}
 
 // =============================================================================
 // simulation.SimulationSystem
@@ -84,8 +89,8 @@ func (ps *ParticleSystem) UpdateEntity(_ any, e *ecs.Entity) error { return ps.a
 func (ps *ParticleSystem) UpdateSimulation(_ any) error { return nil }
 
 // UpdateEntitySimulation advances the particle emitter for a single entity.
-func (ps *ParticleSystem) UpdateEntitySimulation(_ any, e *ecs.Entity) error {
-	return ps.advance(e)
+func (ps *ParticleSystem) UpdateEntitySimulation(world any, e *ecs.Entity) error {
+	return ps.advanceBy(e, ps.timestep(world))
 }
 
 // =============================================================================
@@ -96,20 +101,33 @@ func (ps *ParticleSystem) UpdateEntitySimulation(_ any, e *ecs.Entity) error {
 func (ps *ParticleSystem) UpdateRender(_ any) error { return nil }
 
 // UpdateEntityRender advances the particle emitter for a single entity.
-func (ps *ParticleSystem) UpdateEntityRender(_ any, e *ecs.Entity) error { return ps.advance(e) }
+func (ps *ParticleSystem) UpdateEntityRender(world any, e *ecs.Entity) error {
+	return ps.advanceBy(e, ps.timestep(world))
+}
 
 // =============================================================================
 // Core simulation
 // =============================================================================
 
-// advance is the shared simulation step called by all three interface variants.
+// timestep returns the world's DeltaTime resource in seconds, or DT if it has none.
+func (ps *ParticleSystem) timestep(world any) float64 {
+	if dt, ok := ecs.ResourceFrom[ecs.DeltaTime](world); ok {
+		return dt.Seconds()
+	}
+	return ps.DT
+}
+
+// advance steps the emitter for a single entity by DT.
 func (ps *ParticleSystem) advance(e *ecs.Entity) error {
-	comp, ok := e.Components[ComponentType]
+	return ps.advanceBy(e, ps.DT)
+}
+
+// advanceBy is the shared simulation step called by all three interface variants.
+func (ps *ParticleSystem) advanceBy(e *ecs.Entity, dt float64) error {
+	cfg, ok := ecs.TryGet[EmitterComponent](e)
 	if !ok {
 		return nil
 	}
-	cfg := comp.(EmitterComponent)
-	dt := ps.DT
 
 	// Age and compact existing particles.
 	pool := ps.pools[e]
@@ -139,8 +157,7 @@ func (ps *ParticleSystem) advance(e *ecs.Entity) error {
 		for i := 0; i < cfg.BurstCount && len(pool) < max; i++ {
 			pool = append(pool, ps.spawnOne(cfg))
 		}
-		cfg.BurstCount = 0
-		e.AddComponent(cfg) // write back the cleared burst count
+		ecs.Mutate(e, func(c *EmitterComponent) { c.BurstCount = 0 })
 	}
 
 	// Handle continuous emission.
@@ -195,11 +212,10 @@ func (ps *ParticleSystem) spawnOne(cfg EmitterComponent) particle {
 //	}
 func (ps *ParticleSystem) Draw(screen *ebiten.Image) {
 	for e, pool := range ps.pools {
-		comp, ok := e.Components[ComponentType]
+		cfg, ok := ecs.TryGet[EmitterComponent](e)
 		if !ok {
 			continue
 		}
-		cfg := comp.(EmitterComponent)
 		for _, p := range pool {
 			// t goes 0 (just born) to 1 (about to die).
 			t := 1.0 - p.life/p.totalLife
@@ -0,0 +1,206 @@
+package simulation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mechanical-lich/mlge/ecs"
+)
+
+// SetWorkers sets how many goroutines the manager may use to run systems
+// concurrently. Values of 0 or 1 (the default) run every system on the
+// calling goroutine, in order.
+//
+// With more than one worker, systems that declare their component access
+// through ecs.SystemOptions Reads/Writes are grouped into stages of
+// non-conflicting systems (see ecs.Schedule.Stages):
+//
+//   - In the global pass, the UpdateSimulation calls of a stage run on
+//     separate goroutines.
+//   - In the per-entity pass, the entity list is split into one contiguous
+//     batch per worker and each worker runs every system of the stage on
+//     each of its entities. A stage of one declaring system is simply that
+//     system run in per-entity batches.
+//
+// While a stage runs on several goroutines, component changes to entities of
+// the ecs.World the world argument resolves to are deferred and take effect
+// on the calling goroutine when the stage ends, together with all observer
+// notifications, including ForwardComponentEvents. In the global pass, where
+// several systems may write back to the same entity, every AddComponent and
+// RemoveComponent is deferred (see ecs.World.DeferAllComponentChanges), so a
+// system reads its own write-backs only in later stages. In the per-entity
+// pass each entity belongs to one goroutine, so components it already has
+// are replaced at once and only adding a component type an entity lacks and
+// RemoveComponent are deferred (see ecs.World.DeferComponentChanges). SetTag
+// and ClearTag are deferred in both passes. Spawn and despawn through the
+// world's CommandBuffer, and keep any other state systems share synchronised.
+//
+// Systems that declare no access always run alone on the calling goroutine.
+//
+// The merge is deterministic: when several systems or batches fail, the
+// error reported is the one from the earliest system (in run order) or the
+// earliest batch (in entity order), regardless of goroutine timing. Unlike
+// the serial path, a failing batch does not stop its sibling batches.
+func (m *SimulationSystemManager) SetWorkers(n int) {
+	m.workers = n
+}
+
+// Workers returns the configured worker count.
+func (m *SimulationSystemManager) Workers() int {
+	return m.workers
+}
+
+func (m *SimulationSystemManager) updateSystemsParallel(world any) error {
+	stages, err := m.schedule.RunnableStages(ecs.GlobalPass, world)
+	if err != nil {
+		return err
+	}
+	for _, stage := range stages {
+		if len(stage) == 1 {
+			start := m.profiler.Start()
+			if err := m.systems[stage[0]].UpdateSimulation(world); err != nil {
+				return err
+			}
+			m.record(stage[0], ecs.GlobalPass, time.Since(start), 0)
+			continue
+		}
+		errs := make([]error, len(stage))
+		apply := deferChanges(world, (*ecs.World).DeferAllComponentChanges)
+		var wg sync.WaitGroup
+		for k, i := range stage {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				start := m.profiler.Start()
+				errs[k] = m.systems[i].UpdateSimulation(world)
+				if errs[k] == nil {
+					m.record(i, ecs.GlobalPass, time.Since(start), 0)
+				}
+			}()
+		}
+		wg.Wait()
+		if err := firstError(errs, apply()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateEntitiesParallel runs the per-entity pass stage by stage. entitiesFor
+// returns the candidate entities for a stage.
+func (m *SimulationSystemManager) updateEntitiesParallel(world any, entitiesFor func(stage []int) []*ecs.Entity) error {
+	stages, err := m.schedule.RunnableStages(ecs.EntityPass, world)
+	if err != nil {
+		return err
+	}
+	for _, stage := range stages {
+		entities := entitiesFor(stage)
+		start := m.profiler.Start()
+		counts := m.newStageCounts(stage)
+		if len(stage) == 1 && !m.schedule.Options(stage[0]).DeclaresAccess() {
+			if err := m.runEntities(world, entities, stage, counts); err != nil {
+				return err
+			}
+		} else if err := m.runBatches(world, entities, stage, counts); err != nil {
+			return err
+		}
+		m.recordStage(stage, time.Since(start), counts)
+	}
+	return nil
+}
+
+// newStageCounts returns per-system processed entity counts for one stage,
+// indexed like the stage, or nil when profiling is off.
+func (m *SimulationSystemManager) newStageCounts(stage []int) []int {
+	if m.profiler == nil {
+		return nil
+	}
+	return make([]int, len(stage))
+}
+
+// recordStage records the wall time of a stage's per-entity pass against
+// each of its systems, with that system's entity count.
+func (m *SimulationSystemManager) recordStage(stage []int, d time.Duration, counts []int) {
+	if counts == nil {
+		return
+	}
+	for k, i := range stage {
+		m.record(i, ecs.EntityPass, d, counts[k])
+	}
+}
+
+// runBatches splits entities into one contiguous batch per worker and runs
+// the stage on each batch concurrently.
+func (m *SimulationSystemManager) runBatches(world any, entities []*ecs.Entity, stage []int, counts []int) error {
+	n := len(entities)
+	workers := min(m.workers, n)
+	if workers <= 1 {
+		return m.runEntities(world, entities, stage, counts)
+	}
+	size := (n + workers - 1) / workers
+	errs := make([]error, workers)
+	batchCounts := make([][]int, workers)
+	apply := deferChanges(world, (*ecs.World).DeferComponentChanges)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo, hi := w*size, min((w+1)*size, n)
+		if lo >= hi {
+			break
+		}
+		batchCounts[w] = m.newStageCounts(stage)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[w] = m.runEntities(world, entities[lo:hi], stage, batchCounts[w])
+		}()
+	}
+	wg.Wait()
+	for _, bc := range batchCounts {
+		for k, c := range bc {
+			counts[k] += c
+		}
+	}
+	return firstError(errs, apply())
+}
+
+// runEntities runs every system of stage, in order, on each entity that
+// satisfies its requirements. When counts is non-nil the entities each
+// system processed are counted into it.
+func (m *SimulationSystemManager) runEntities(world any, entities []*ecs.Entity, stage []int, counts []int) error {
+	for _, entity := range entities {
+		if ecs.IsInanimate(entity) {
+			continue
+		}
+		for k, i := range stage {
+			if entity.HasComponentsSlice(m.cachedRequirements[i]) {
+				if err := m.systems[i].UpdateEntitySimulation(world, entity); err != nil {
+					return err
+				}
+				if counts != nil {
+					counts[k]++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// deferChanges defers component changes with how on the ecs.World that
+// world resolves to, if any, while a stage runs in parallel. The returned
+// function applies them.
+func deferChanges(world any, how func(*ecs.World) func() error) func() error {
+	if w, ok := ecs.WorldFrom(world); ok {
+		return how(w)
+	}
+	return func() error { return nil }
+}
+
+// firstError returns the first non-nil error of errs, or else applyErr.
+func firstError(errs []error, applyErr error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return applyErr
+}
@@ -2,7 +2,9 @@ package simulation
 
 import (
 	"context"
+	"errors"
 	"log"
+	"slices"
 	"time"
 
 	"github.com/mechanical-lich/mlge/ecs"
@@ -19,6 +21,11 @@ type ServerConfig struct {
 	// A value of 1 sends a snapshot every tick. A value of 3 sends one every 3 ticks.
 	// Defaults to 1 if zero.
 	SnapshotEvery int
+
+	// Workers is the number of goroutines used to run systems that declare
+	// their component access (see SimulationSystemManager.SetWorkers).
+	// 0 or 1 runs every system on the simulation goroutine.
+	Workers int
 }
 
 func (c *ServerConfig) tickRate() int {
@@ -39,8 +46,22 @@ func (c *ServerConfig) snapshotEvery() int {
 // list of simulated entities. Typically this returns level.Entities or similar.
 // Using a func instead of a field avoids holding a stale pointer if the entity
 // slice is reallocated during world generation.
+//
+// When the world passed to [NewServer] is (or provides) an *ecs.World, the
+// method value world.Entities can be used directly, or the EntitySource can be
+// left nil to use it automatically.
 type EntitySource func() []*ecs.Entity
 
+// clientTick records the last prediction tick a client stamped on a command
+// and the server tick that command was processed on.
+type clientTick struct {
+	tick, at uint64
+}
+
+// InterestFunc reports whether entity e is relevant to client, i.e. whether
+// it belongs in the snapshot sent to that client. See [Server.SetInterest].
+type InterestFunc func(client transport.ClientID, e *ecs.Entity) bool
+
 // Server runs the authoritative game simulation loop in a dedicated goroutine.
 //
 // It owns the game world, drives SimulationSystems at a fixed tick rate,
@@ -65,8 +86,13 @@ type Server struct {
 	config        ServerConfig
 	world         any
 	entitySource  EntitySource
+	ecsWorld      *ecs.World // set when world resolves to an ecs.World; its command buffer is flushed each pass
+	useQueries    bool       // entities come from ecsWorld; enables query-driven passes
 	transport     transport.ServerTransport
 	codec         transport.SnapshotCodec
+	interest      InterestFunc
+	clients       []transport.ClientID // connected clients, in connection order
+	predicted     map[transport.ClientID]clientTick
 	systems       SimulationSystemManager
 	stateMachine  SimulationStateMachine
 	tick          uint64
@@ -77,6 +103,15 @@ type Server struct {
 
 // NewServer creates a Server but does not start the loop.
 // Call AddSystem, SetState, then Run (typically in a goroutine).
+//
+// If entitySource is nil and world is an *ecs.World (or implements
+// ecs.WorldProvider), the world's live entity set is used and the per-entity
+// system pass walks cached ecs.World queries.
+//
+// Whenever world resolves to an *ecs.World, its CommandBuffer is applied after
+// the global system pass, after the per-entity pass and after the state
+// machine tick, so structural changes recorded by systems land before the
+// next pass and before the snapshot is encoded.
 func NewServer(
 	config ServerConfig,
 	world any,
@@ -84,17 +119,26 @@ func NewServer(
 	t transport.ServerTransport,
 	codec transport.SnapshotCodec,
 ) *Server {
+	ecsWorld, _ := ecs.WorldFrom(world)
+	useQueries := entitySource == nil && ecsWorld != nil
+	if useQueries {
+		entitySource = ecsWorld.Entities
+	}
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Server{
+	srv := &Server{
 		config:        config,
 		world:         world,
 		entitySource:  entitySource,
+		ecsWorld:      ecsWorld,
+		useQueries:    useQueries,
 		transport:     t,
 		codec:         codec,
 		snapshotEvery: config.snapshotEvery(),
 		ctx:           ctx,
 		cancel:        cancel,
 	}
+	srv.systems.SetWorkers(config.Workers)
+	return srv
 }
 
 // AddSystem registers a SimulationSystem. Call before Run.
@@ -102,6 +146,73 @@ func (s *Server) AddSystem(sys SimulationSystem) {
 	s.systems.AddSystem(sys)
 }
 
+// AddSystemWithOptions registers a SimulationSystem with explicit scheduling
+// options (name, phase, Before/After constraints). Call before Run.
+func (s *Server) AddSystemWithOptions(sys SimulationSystem, opts ecs.SystemOptions) {
+	s.systems.AddSystemWithOptions(sys, opts)
+}
+
+// ResolveSystems resolves the system run order and returns any ordering
+// error (cycle, duplicate name). Call after registering systems to fail fast
+// instead of logging the error every tick.
+func (s *Server) ResolveSystems() error {
+	return s.systems.Resolve()
+}
+
+// SetSystemEnabled enables or disables the system registered under name (see
+// SimulationSystemManager.SetEnabled). When the server runs in its own
+// goroutine, call this from that goroutine, e.g. from a SimulationState or a
+// system, not from the render loop.
+func (s *Server) SetSystemEnabled(name string, enabled bool) error {
+	return s.systems.SetEnabled(name, enabled)
+}
+
+// SetProfiler records per-system timings for every tick in p (see
+// SimulationSystemManager.SetProfiler) along with the wall time of each whole
+// tick. If p has no Budget, it is set to the tick interval so overruns are
+// counted. Pass nil to stop profiling. Call before Run.
+func (s *Server) SetProfiler(p *ecs.Profiler) {
+	if p != nil && p.Budget == 0 {
+		p.Budget = s.tickInterval()
+	}
+	s.systems.SetProfiler(p)
+}
+
+// Profiler returns the profiler set with SetProfiler, or nil.
+func (s *Server) Profiler() *ecs.Profiler {
+	return s.systems.Profiler()
+}
+
+// SetInterest filters snapshots per client: each connected client is sent
+// its own snapshot, encoded from only the entities f reports as relevant to
+// it, such as those near the entity it controls or visible to its team.
+// Clients then neither receive the whole world nor learn about entities
+// hidden from them. Pass nil to broadcast one snapshot to every client again.
+//
+// An entity that leaves a client's interest is simply absent from that
+// client's next full snapshot, so the client's SnapshotCodec must delete
+// local entities missing from a full snapshot, or it keeps showing them where
+// they were last seen. DeltaServerTransport lists them in Snapshot.Removed.
+//
+// The transport must implement transport.ClientSnapshotSender and report
+// CanSendTo, as LocalTransport, TCPServerTransport and a DeltaServerTransport
+// wrapping either do; otherwise an error is returned. f runs on the simulation goroutine once per client
+// and entity each time a snapshot is sent. Call before Run.
+func (s *Server) SetInterest(f InterestFunc) error {
+	if sender, ok := s.transport.(transport.ClientSnapshotSender); f != nil && (!ok || !sender.CanSendTo()) {
+		return errors.New("simulation: transport cannot send snapshots to individual clients")
+	}
+	s.interest = f
+	return nil
+}
+
+// Clients returns the IDs of the connected clients, in the order they
+// connected. It is updated from the transport's connection notifications at
+// the start of each tick.
+func (s *Server) Clients() []transport.ClientID {
+	return slices.Clone(s.clients)
+}
+
 // SetState sets the initial SimulationState. Call before Run.
 // If not set, the server runs systems without state machine logic.
 func (s *Server) SetState(state SimulationState) {
@@ -113,8 +224,7 @@ func (s *Server) SetState(state SimulationState) {
 // Use this for independent (decoupled) tick rates. Intended to run in a goroutine.
 func (s *Server) Run() {
 	tickRate := s.config.tickRate()
-	interval := time.Duration(float64(time.Second) / float64(tickRate))
-	ticker := time.NewTicker(interval)
+	ticker := time.NewTicker(s.tickInterval())
 	defer ticker.Stop()
 	defer s.transport.Close()
 
@@ -156,30 +266,137 @@ func (s *Server) Tick() uint64 {
 	return s.tick
 }
 
+func (s *Server) tickInterval() time.Duration {
+	return time.Duration(float64(time.Second) / float64(s.config.tickRate()))
+}
+
 func (s *Server) step() {
+	if p := s.systems.Profiler(); p != nil {
+		start := time.Now()
+		defer func() { p.RecordTick(time.Since(start)) }()
+	}
 	s.tick++
 
 	// 1. Drain and process commands from clients.
 	cmds := s.transport.ReceiveCommands()
+	s.trackClients(cmds)
 	s.stateMachine.ProcessCommands(cmds)
 
 	// 2. Run global system pass.
 	if err := s.systems.UpdateSystems(s.world); err != nil {
 		log.Printf("[simulation] tick %d UpdateSystems error: %v", s.tick, err)
 	}
+	s.flushCommands("UpdateSystems")
 
 	// 3. Run per-entity system pass.
-	entities := s.entitySource()
-	if err := s.systems.UpdateSystemsForEntities(s.world, entities); err != nil {
+	var err error
+	if s.useQueries {
+		err = s.systems.UpdateSystemsForWorld(s.world, s.ecsWorld)
+	} else {
+		err = s.systems.UpdateSystemsForEntities(s.world, s.entitySource())
+	}
+	if err != nil {
 		log.Printf("[simulation] tick %d UpdateSystemsForEntities error: %v", s.tick, err)
 	}
+	s.flushCommands("UpdateSystemsForEntities")
 
 	// 4. Advance state machine.
 	s.stateMachine.Tick(s.world)
+	s.flushCommands("state Tick")
 
 	// 5. Send snapshot if it's time.
 	if s.tick%uint64(s.snapshotEvery) == 0 {
-		snapshot := s.codec.Encode(s.tick, entities)
-		s.transport.SendSnapshot(snapshot)
+		s.sendSnapshots()
+	}
+}
+
+// trackClients updates the connected client list from the connection
+// notifications in cmds, and records the prediction tick of each client's
+// latest stamped command.
+func (s *Server) trackClients(cmds []*transport.Command) {
+	for _, cmd := range cmds {
+		switch cmd.Type {
+		case transport.CommandClientConnected:
+			s.clients = append(s.clients, cmd.ClientID)
+		case transport.CommandClientDisconnected:
+			if i := slices.Index(s.clients, cmd.ClientID); i >= 0 {
+				s.clients = slices.Delete(s.clients, i, i+1)
+			}
+			delete(s.predicted, cmd.ClientID)
+		case transport.CommandSnapshotAck:
+		default:
+			if cmd.Tick == 0 {
+				continue
+			}
+			if s.predicted == nil {
+				s.predicted = make(map[transport.ClientID]clientTick)
+			}
+			s.predicted[cmd.ClientID] = clientTick{tick: cmd.Tick, at: s.tick}
+		}
+	}
+}
+
+// clientTick returns the prediction tick of client id that corresponds to
+// the current server tick, or false if it has not sent a stamped command.
+func (s *Server) clientTick(id transport.ClientID) (uint64, bool) {
+	last, ok := s.predicted[id]
+	if !ok {
+		return 0, false
+	}
+	return last.tick + s.tick - last.at, true
+}
+
+// sendSnapshots broadcasts one snapshot, or with an InterestFunc set, sends
+// every client a snapshot of the entities relevant to it. Each client is
+// only told its own prediction tick when the transport can address it;
+// otherwise the broadcast carries every predicting client's tick.
+func (s *Server) sendSnapshots() {
+	entities := s.entitySource()
+	if s.interest == nil {
+		snap := s.codec.Encode(s.tick, entities)
+		sender, ok := s.transport.(transport.ClientSnapshotSender)
+		if len(s.predicted) == 0 || !ok || !sender.CanSendTo() {
+			if len(s.predicted) > 0 {
+				snap.ClientTicks = make(map[transport.ClientID]uint64, len(s.predicted))
+				for id := range s.predicted {
+					snap.ClientTicks[id], _ = s.clientTick(id)
+				}
+			}
+			s.transport.SendSnapshot(snap)
+			return
+		}
+		for _, id := range s.clients {
+			own := *snap
+			if tick, ok := s.clientTick(id); ok {
+				own.ClientTicks = map[transport.ClientID]uint64{id: tick}
+			}
+			sender.SendSnapshotTo(id, &own)
+		}
+		return
+	}
+	sender := s.transport.(transport.ClientSnapshotSender)
+	for _, id := range s.clients {
+		relevant := make([]*ecs.Entity, 0, len(entities))
+		for _, e := range entities {
+			if s.interest(id, e) {
+				relevant = append(relevant, e)
+			}
+		}
+		snap := s.codec.Encode(s.tick, relevant)
+		if tick, ok := s.clientTick(id); ok {
+			snap.ClientTicks = map[transport.ClientID]uint64{id: tick}
+		}
+		sender.SendSnapshotTo(id, snap)
+	}
+}
+
+// flushCommands applies structural changes deferred to the world's command
+// buffer during the named pass.
+func (s *Server) flushCommands(pass string) {
+	if s.ecsWorld == nil {
+		return
+	}
+	if err := s.ecsWorld.FlushCommands(); err != nil {
+		log.Printf("[simulation] tick %d %s command flush error: %v", s.tick, pass, err)
 	}
 }
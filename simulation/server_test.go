@@ -0,0 +1,116 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/mechanical-lich/mlge/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blueprintCodec encodes entities by blueprint only.
+type blueprintCodec struct{}
+
+func (blueprintCodec) Encode(tick uint64, entities []*ecs.Entity) *transport.Snapshot {
+	es := make([]*transport.EntitySnapshot, 0, len(entities))
+	for _, e := range entities {
+		es = append(es, &transport.EntitySnapshot{ID: e.ID.String(), Blueprint: e.Blueprint})
+	}
+	return transport.NewSnapshot(tick, es)
+}
+
+func (blueprintCodec) Decode(*transport.Snapshot, any) {}
+
+func snapshotBlueprints(s *transport.Snapshot) []string {
+	var names []string
+	for _, es := range s.Entities {
+		names = append(names, es.Blueprint)
+	}
+	return names
+}
+
+// broadcastOnly is a ServerTransport that cannot address single clients.
+type broadcastOnly struct{ transport.ServerTransport }
+
+func TestServerFiltersSnapshotsPerClient(t *testing.T) {
+	w := ecs.NewWorld()
+	w.Add(&ecs.Entity{Blueprint: "red"})
+	w.Add(&ecs.Entity{Blueprint: "blue"})
+	w.Add(&ecs.Entity{Blueprint: "red"})
+
+	srvT := transport.NewLocalServerTransport()
+	redTeam, blueTeam := srvT.Connect(), srvT.Connect()
+	srv := NewServer(ServerConfig{}, w, nil, srvT, blueprintCodec{})
+	require.NoError(t, srv.SetInterest(func(client transport.ClientID, e *ecs.Entity) bool {
+		return (client == 1) == (e.Blueprint == "red")
+	}))
+
+	srv.Step()
+	assert.Equal(t, []transport.ClientID{1, 2}, srv.Clients())
+	assert.Equal(t, []string{"red", "red"}, snapshotBlueprints(redTeam.ReceiveSnapshot()))
+	assert.Equal(t, []string{"blue"}, snapshotBlueprints(blueTeam.ReceiveSnapshot()))
+
+	blueTeam.Close()
+	srv.Step()
+	assert.Equal(t, []transport.ClientID{1}, srv.Clients())
+
+	assert.NoError(t, srv.SetInterest(nil))
+}
+
+func TestSetInterestNeedsPerClientTransport(t *testing.T) {
+	srvT, _ := transport.NewLocalTransport()
+	srv := NewServer(ServerConfig{}, ecs.NewWorld(), nil, broadcastOnly{srvT}, blueprintCodec{})
+	assert.Error(t, srv.SetInterest(func(transport.ClientID, *ecs.Entity) bool { return true }))
+	assert.NoError(t, srv.SetInterest(nil))
+
+	delta := transport.NewDeltaServerTransport(broadcastOnly{srvT})
+	srv = NewServer(ServerConfig{}, ecs.NewWorld(), nil, delta, blueprintCodec{})
+	assert.Error(t, srv.SetInterest(func(transport.ClientID, *ecs.Entity) bool { return true }), "Expected a delta wrapper to report what it wraps")
+
+	delta = transport.NewDeltaServerTransport(srvT)
+	srv = NewServer(ServerConfig{}, ecs.NewWorld(), nil, delta, blueprintCodec{})
+	assert.NoError(t, srv.SetInterest(func(transport.ClientID, *ecs.Entity) bool { return true }))
+}
+
+func TestServerBroadcastsClientTicksWithoutAddressing(t *testing.T) {
+	srvT, cliT := transport.NewLocalTransport()
+	srv := NewServer(ServerConfig{}, ecs.NewWorld(), nil, broadcastOnly{srvT}, blueprintCodec{})
+
+	cliT.SendCommand(&transport.Command{Type: "move", Tick: 3})
+	srv.Step()
+	assert.Equal(t, map[transport.ClientID]uint64{1: 3}, cliT.ReceiveSnapshot().ClientTicks)
+}
+
+func TestServerReportsClientTicks(t *testing.T) {
+	assert := assert.New(t)
+	srvT := transport.NewLocalServerTransport()
+	a, b := srvT.Connect(), srvT.Connect()
+	srv := NewServer(ServerConfig{}, ecs.NewWorld(), nil, srvT, blueprintCodec{})
+
+	srv.Step()
+	assert.Nil(a.ReceiveSnapshot().ClientTicks, "Expected no ticks before any predicted command")
+	b.ReceiveSnapshot()
+
+	a.SendCommand(&transport.Command{Type: "move", Tick: 40})
+	b.SendCommand(&transport.Command{Type: "move"})
+	srv.Step()
+	a.SendCommand(&transport.Command{Type: transport.CommandSnapshotAck, Tick: 2})
+	srv.Step()
+	assert.Equal(map[transport.ClientID]uint64{1: 41}, a.ReceiveSnapshot().ClientTicks)
+	assert.Nil(b.ReceiveSnapshot().ClientTicks, "Expected broadcasts not to reveal other clients' ticks")
+
+	b.SendCommand(&transport.Command{Type: "move", Tick: 7})
+	srv.Step()
+	assert.Equal(map[transport.ClientID]uint64{1: 42}, a.ReceiveSnapshot().ClientTicks)
+	assert.Equal(map[transport.ClientID]uint64{2: 7}, b.ReceiveSnapshot().ClientTicks)
+
+	require.NoError(t, srv.SetInterest(func(transport.ClientID, *ecs.Entity) bool { return true }))
+	srv.Step()
+	assert.Equal(map[transport.ClientID]uint64{1: 43}, a.ReceiveSnapshot().ClientTicks)
+	assert.Equal(map[transport.ClientID]uint64{2: 8}, b.ReceiveSnapshot().ClientTicks, "Expected each client to see only its own tick")
+
+	a.Close()
+	srv.Step()
+	assert.NotContains(b.ReceiveSnapshot().ClientTicks, transport.ClientID(1), "Expected disconnected clients to be forgotten")
+}
@@ -27,6 +27,28 @@ type SimulationState interface {
 	Done() bool
 }
 
+// ConnectionHandler is an optional interface for SimulationStates that track
+// players. When the current state implements it, the
+// transport.CommandClientConnected and transport.CommandClientDisconnected
+// notifications are routed to these methods instead of ProcessCommand, in
+// order with the commands around them.
+//
+// Every later command from the client carries the same ClientID, so states
+// typically map it to the entity that player controls:
+//
+//	func (g *Gameplay) ClientConnected(id transport.ClientID) {
+//	    g.players[id] = g.spawnPlayer()
+//	}
+//
+//	func (g *Gameplay) ProcessCommand(cmd *transport.Command) {
+//	    player := g.players[cmd.ClientID]
+//	    ...
+//	}
+type ConnectionHandler interface {
+	ClientConnected(id transport.ClientID)
+	ClientDisconnected(id transport.ClientID)
+}
+
 // SimulationStateMachine is a minimal stack-based state machine for the
 // server side. It mirrors state.StateMachine but has no Draw method.
 type SimulationStateMachine struct {
@@ -64,12 +86,22 @@ func (m *SimulationStateMachine) Tick(world any) bool {
 }
 
 // ProcessCommands routes all pending commands to the current state.
+// Connection notifications go to its ConnectionHandler methods when it has
+// them.
 func (m *SimulationStateMachine) ProcessCommands(cmds []*transport.Command) {
 	if len(m.states) == 0 {
 		return
 	}
 	top := m.states[len(m.states)-1]
+	handler, _ := top.(ConnectionHandler)
 	for _, cmd := range cmds {
-		top.ProcessCommand(cmd)
+		switch {
+		case handler != nil && cmd.Type == transport.CommandClientConnected:
+			handler.ClientConnected(cmd.ClientID)
+		case handler != nil && cmd.Type == transport.CommandClientDisconnected:
+			handler.ClientDisconnected(cmd.ClientID)
+		default:
+			top.ProcessCommand(cmd)
+		}
 	}
 }
@@ -0,0 +1,48 @@
+package simulation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mechanical-lich/mlge/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingState struct{ log []string }
+
+func (s *recordingState) Tick(any) SimulationState { return nil }
+func (s *recordingState) Done() bool               { return false }
+
+func (s *recordingState) ProcessCommand(cmd *transport.Command) {
+	s.log = append(s.log, fmt.Sprintf("%s from %d", cmd.Type, cmd.ClientID))
+}
+
+type lobbyState struct{ recordingState }
+
+func (s *lobbyState) ClientConnected(id transport.ClientID) {
+	s.log = append(s.log, fmt.Sprintf("joined %d", id))
+}
+
+func (s *lobbyState) ClientDisconnected(id transport.ClientID) {
+	s.log = append(s.log, fmt.Sprintf("left %d", id))
+}
+
+func TestProcessCommandsRoutesConnections(t *testing.T) {
+	cmds := []*transport.Command{
+		{Type: transport.CommandClientConnected, ClientID: 2},
+		{Type: "move", ClientID: 2},
+		{Type: transport.CommandClientDisconnected, ClientID: 2},
+	}
+
+	lobby := &lobbyState{}
+	var m SimulationStateMachine
+	m.PushState(lobby)
+	m.ProcessCommands(cmds)
+	assert.Equal(t, []string{"joined 2", "move from 2", "left 2"}, lobby.log)
+
+	plain := &recordingState{}
+	m.PushState(plain)
+	m.ProcessCommands(cmds)
+	assert.Equal(t, []string{"client.connected from 2", "move from 2", "client.disconnected from 2"}, plain.log,
+		"Expected states without a ConnectionHandler to receive notifications as commands")
+}
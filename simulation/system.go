@@ -1,6 +1,10 @@
 package simulation
 
-import "github.com/mechanical-lich/mlge/ecs"
+import (
+	"time"
+
+	"github.com/mechanical-lich/mlge/ecs"
+)
 
 // SimulationSystem is the server-side counterpart to ecs.SystemInterface.
 //
@@ -29,28 +33,93 @@ type SimulationSystem interface {
 // drives them each server tick. It mirrors ecs.SystemManager in structure
 // but is typed to SimulationSystem so the compiler prevents accidentally
 // adding a render-side system here.
+//
+// Systems run ordered by their ecs.SystemOptions: by phase, then by
+// Before/After constraints, then in registration order.
 type SimulationSystemManager struct {
 	systems            []SimulationSystem
 	cachedRequirements [][]ecs.ComponentType
+	schedule           ecs.Schedule
+	queryWorld         *ecs.World
+	queries            []*ecs.Query
+	workers            int
+	profiler           *ecs.Profiler
 }
 
-// AddSystem appends a system to the end of the execution order.
-// Systems run in the order they are added each tick.
+// AddSystem registers a system using the options it declares via
+// ecs.OptionedSystem, if any. Unconstrained systems run in the order they
+// are added.
 func (m *SimulationSystemManager) AddSystem(s SimulationSystem) {
+	m.AddSystemWithOptions(s, ecs.OptionsFor(s))
+}
+
+// AddSystemWithOptions registers a system with explicit scheduling options.
+// Ordering errors (cycles, duplicate names) are reported by Resolve and by
+// the Update methods.
+func (m *SimulationSystemManager) AddSystemWithOptions(s SimulationSystem, opts ecs.SystemOptions) {
 	if m.systems == nil {
 		m.systems = make([]SimulationSystem, 0)
 		m.cachedRequirements = make([][]ecs.ComponentType, 0)
 	}
 	m.systems = append(m.systems, s)
 	m.cachedRequirements = append(m.cachedRequirements, s.Requires())
+	m.schedule.Add(opts)
+}
+
+// SetEnabled enables or disables the system registered under name (its
+// ecs.SystemOptions Name) without removing it, e.g. to pause weather during a
+// cutscene. Returns an error if no system has that name.
+func (m *SimulationSystemManager) SetEnabled(name string, enabled bool) error {
+	return m.schedule.SetEnabled(name, enabled)
+}
+
+// Enabled reports whether the system registered under name is enabled.
+func (m *SimulationSystemManager) Enabled(name string) bool {
+	return m.schedule.Enabled(name)
+}
+
+// Resolve resolves the run order now and returns any ordering error.
+// Useful to validate system registration at startup.
+func (m *SimulationSystemManager) Resolve() error {
+	_, err := m.schedule.Order()
+	return err
+}
+
+// SetProfiler records per-system timings in p for every update pass (see
+// ecs.Profiler). Pass nil to stop profiling. The systems of a parallel stage
+// run interleaved per entity, so each is recorded with the wall time of the
+// whole stage's per-entity pass rather than a time of its own.
+func (m *SimulationSystemManager) SetProfiler(p *ecs.Profiler) {
+	m.profiler = p
+}
+
+// Profiler returns the profiler set with SetProfiler, or nil.
+func (m *SimulationSystemManager) Profiler() *ecs.Profiler {
+	return m.profiler
+}
+
+// record adds a timing sample for system i if profiling is enabled.
+func (m *SimulationSystemManager) record(i int, pass ecs.Pass, d time.Duration, entities int) {
+	if m.profiler != nil {
+		m.profiler.Record(i, ecs.SystemName(m.systems[i], m.schedule.Options(i)), pass, d, entities)
+	}
 }
 
 // UpdateSystems calls UpdateSimulation on every registered system.
 func (m *SimulationSystemManager) UpdateSystems(world any) error {
-	for _, s := range m.systems {
-		if err := s.UpdateSimulation(world); err != nil {
+	if m.workers > 1 {
+		return m.updateSystemsParallel(world)
+	}
+	order, err := m.schedule.Runnable(ecs.GlobalPass, world)
+	if err != nil {
+		return err
+	}
+	for _, i := range order {
+		start := m.profiler.Start()
+		if err := m.systems[i].UpdateSimulation(world); err != nil {
 			return err
 		}
+		m.record(i, ecs.GlobalPass, time.Since(start), 0)
 	}
 	return nil
 }
@@ -58,21 +127,71 @@ func (m *SimulationSystemManager) UpdateSystems(world any) error {
 // UpdateSystemsForEntities iterates every system, then every entity, calling
 // UpdateEntitySimulation when the entity satisfies the system's Requires().
 //
-// Entities with the ecs.InanimateComponentType are skipped when that global
-// is set, matching the behaviour of ecs.SystemManager.UpdateSystemsForEntities.
+// Entities for which ecs.IsInanimate reports true are skipped, matching the behaviour of ecs.SystemManager.UpdateSystemsForEntities.
 func (m *SimulationSystemManager) UpdateSystemsForEntities(world any, entities []*ecs.Entity) error {
-	for i, s := range m.systems {
+	if m.workers > 1 {
+		return m.updateEntitiesParallel(world, func([]int) []*ecs.Entity { return entities })
+	}
+	order, err := m.schedule.Runnable(ecs.EntityPass, world)
+	if err != nil {
+		return err
+	}
+	for _, i := range order {
+		s := m.systems[i]
 		required := m.cachedRequirements[i]
+		start := m.profiler.Start()
+		processed := 0
 		for _, entity := range entities {
-			if ecs.InanimateComponentType != "" && entity.HasComponent(ecs.InanimateComponentType) {
+			if ecs.IsInanimate(entity) {
 				continue
 			}
 			if entity.HasComponentsSlice(required) {
 				if err := s.UpdateEntitySimulation(world, entity); err != nil {
 					return err
 				}
+				processed++
+			}
+		}
+		m.record(i, ecs.EntityPass, time.Since(start), processed)
+	}
+	return nil
+}
+
+// UpdateSystemsForWorld is the query-driven form of UpdateSystemsForEntities.
+// Each system walks the cached ecs.World.Query for its Requires() instead of
+// testing every entity, so the cost scales with matching entities only.
+func (m *SimulationSystemManager) UpdateSystemsForWorld(world any, w *ecs.World) error {
+	if m.queryWorld != w || len(m.queries) != len(m.systems) {
+		m.queryWorld = w
+		m.queries = ecs.WorldQueries(w, m.cachedRequirements)
+	}
+	if m.workers > 1 {
+		return m.updateEntitiesParallel(world, func(stage []int) []*ecs.Entity {
+			if len(stage) == 1 {
+				return m.queries[stage[0]].Entities()
 			}
+			return w.Entities()
+		})
+	}
+	order, err := m.schedule.Runnable(ecs.EntityPass, world)
+	if err != nil {
+		return err
+	}
+	for _, i := range order {
+		s := m.systems[i]
+		start := m.profiler.Start()
+		processed := 0
+		err := m.queries[i].Each(func(entity *ecs.Entity) error {
+			if ecs.IsInanimate(entity) {
+				return nil
+			}
+			processed++
+			return s.UpdateEntitySimulation(world, entity)
+		})
+		if err != nil {
+			return err
 		}
+		m.record(i, ecs.EntityPass, time.Since(start), processed)
 	}
 	return nil
 }
@@ -0,0 +1,428 @@
+package simulation
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/mechanical-lich/mlge/event"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	positionType ecs.ComponentType = "Position"
+	velocityType ecs.ComponentType = "Velocity"
+	healthType   ecs.ComponentType = "Health"
+)
+
+type position struct{ X, Y float64 }
+
+func (position) GetType() ecs.ComponentType { return positionType }
+
+type velocity struct{ X, Y float64 }
+
+func (velocity) GetType() ecs.ComponentType { return velocityType }
+
+type health struct{ HP int }
+
+func (health) GetType() ecs.ComponentType { return healthType }
+
+// moveSystem writes Position from Velocity using value write-back.
+type moveSystem struct{ globalCalls atomic.Int32 }
+
+func (s *moveSystem) Requires() []ecs.ComponentType {
+	return []ecs.ComponentType{positionType, velocityType}
+}
+
+func (s *moveSystem) UpdateSimulation(any) error {
+	s.globalCalls.Add(1)
+	return nil
+}
+
+func (s *moveSystem) UpdateEntitySimulation(_ any, e *ecs.Entity) error {
+	vel := ecs.Get[velocity](e)
+	ecs.Mutate(e, func(p *position) {
+		p.X += vel.X
+		p.Y += vel.Y
+	})
+	return nil
+}
+
+func (s *moveSystem) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{
+		Name:   "move",
+		Reads:  []ecs.ComponentType{velocityType},
+		Writes: []ecs.ComponentType{positionType},
+	}
+}
+
+// regenSystem writes Health only, so it never conflicts with moveSystem.
+type regenSystem struct{ globalCalls atomic.Int32 }
+
+func (s *regenSystem) Requires() []ecs.ComponentType { return []ecs.ComponentType{healthType} }
+
+func (s *regenSystem) UpdateSimulation(any) error {
+	s.globalCalls.Add(1)
+	return nil
+}
+
+func (s *regenSystem) UpdateEntitySimulation(_ any, e *ecs.Entity) error {
+	ecs.Mutate(e, func(h *health) { h.HP++ })
+	return nil
+}
+
+func (s *regenSystem) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{Name: "regen", Writes: []ecs.ComponentType{healthType}}
+}
+
+// countingSystem declares no access and uses unsynchronised state; the race
+// detector flags it if the manager ever runs it concurrently.
+type countingSystem struct{ entities int }
+
+func (s *countingSystem) Requires() []ecs.ComponentType { return nil }
+
+func (s *countingSystem) UpdateSimulation(any) error { return nil }
+
+func (s *countingSystem) UpdateEntitySimulation(any, *ecs.Entity) error {
+	s.entities++
+	return nil
+}
+
+// failingSystem fails on every entity whose health is at or above a threshold.
+type failingSystem struct{ threshold int }
+
+func (s *failingSystem) Requires() []ecs.ComponentType { return []ecs.ComponentType{healthType} }
+
+func (s *failingSystem) UpdateSimulation(any) error { return nil }
+
+func (s *failingSystem) UpdateEntitySimulation(_ any, e *ecs.Entity) error {
+	if hp := ecs.Get[health](e).HP; hp >= s.threshold {
+		return fmt.Errorf("hp %d", hp)
+	}
+	return nil
+}
+
+func (s *failingSystem) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{Reads: []ecs.ComponentType{healthType}}
+}
+
+// launchSystem gives every entity without a Velocity one, which adds the
+// entity to the world's Velocity queries.
+type launchSystem struct{}
+
+func (launchSystem) Requires() []ecs.ComponentType { return []ecs.ComponentType{positionType} }
+
+func (launchSystem) UpdateSimulation(any) error { return nil }
+
+func (launchSystem) UpdateEntitySimulation(_ any, e *ecs.Entity) error {
+	if !e.HasComponent(velocityType) {
+		e.AddComponent(velocity{X: 1})
+	}
+	return nil
+}
+
+func (launchSystem) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{Name: "launch", Writes: []ecs.ComponentType{velocityType}}
+}
+
+func buildWorld(n int) *ecs.World {
+	w := ecs.NewWorld()
+	for i := 0; i < n; i++ {
+		e := w.Spawn()
+		e.AddComponent(position{})
+		e.AddComponent(velocity{X: float64(i), Y: 1})
+		e.AddComponent(health{HP: i % 10})
+	}
+	return w
+}
+
+func runTicks(t *testing.T, m *SimulationSystemManager, w *ecs.World, ticks int, useQueries bool) {
+	for i := 0; i < ticks; i++ {
+		assert.Nil(t, m.UpdateSystems(w))
+		var err error
+		if useQueries {
+			err = m.UpdateSystemsForWorld(w, w)
+		} else {
+			err = m.UpdateSystemsForEntities(w, w.Entities())
+		}
+		assert.Nil(t, err)
+	}
+}
+
+func TestStagesGroupNonConflictingSystems(t *testing.T) {
+	m := &SimulationSystemManager{}
+	m.AddSystem(&moveSystem{})
+	m.AddSystem(&regenSystem{})
+	m.AddSystem(&countingSystem{})
+
+	stages, err := m.schedule.Stages()
+
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{0, 1}, {2}}, stages)
+}
+
+// TestParallelMatchesSerial runs the same systems serially and with several
+// workers and checks the resulting world state is identical. Run with -race.
+func TestParallelMatchesSerial(t *testing.T) {
+	for _, useQueries := range []bool{false, true} {
+		serialWorld := buildWorld(1000)
+		serial := &SimulationSystemManager{}
+		serial.AddSystem(&moveSystem{})
+		serial.AddSystem(&regenSystem{})
+		serialCounter := &countingSystem{}
+		serial.AddSystem(serialCounter)
+		runTicks(t, serial, serialWorld, 5, useQueries)
+
+		parallelWorld := buildWorld(1000)
+		parallel := &SimulationSystemManager{}
+		move, regen := &moveSystem{}, &regenSystem{}
+		parallel.AddSystem(move)
+		parallel.AddSystem(regen)
+		parallelCounter := &countingSystem{}
+		parallel.AddSystem(parallelCounter)
+		parallel.SetWorkers(4)
+		runTicks(t, parallel, parallelWorld, 5, useQueries)
+
+		assert.Equal(t, int32(5), move.globalCalls.Load())
+		assert.Equal(t, int32(5), regen.globalCalls.Load())
+		assert.Equal(t, serialCounter.entities, parallelCounter.entities)
+		for _, e := range serialWorld.Entities() {
+			p := parallelWorld.Get(e.ID)
+			assert.Equal(t, ecs.Get[position](e), ecs.Get[position](p))
+			assert.Equal(t, ecs.Get[health](e), ecs.Get[health](p))
+		}
+	}
+}
+
+func TestParallelErrorIsDeterministic(t *testing.T) {
+	w := buildWorld(100)
+	m := &SimulationSystemManager{}
+	m.AddSystem(&failingSystem{threshold: 7})
+	m.SetWorkers(8)
+
+	for i := 0; i < 20; i++ {
+		err := m.UpdateSystemsForEntities(w, w.Entities())
+		assert.EqualError(t, err, "hp 7", "Expected the first failing entity's error every time")
+	}
+}
+
+func TestProfilingCountsEntitiesSerialAndParallel(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		w := buildWorld(100)
+		w.Spawn().AddComponent(position{}) // matches only countingSystem
+		m := &SimulationSystemManager{}
+		m.AddSystem(&moveSystem{})
+		m.AddSystem(&regenSystem{})
+		m.AddSystem(&countingSystem{})
+		m.SetWorkers(workers)
+		p := ecs.NewProfiler(0)
+		m.SetProfiler(p)
+		runTicks(t, m, w, 3, true)
+
+		r := p.Report()
+		assert.Len(t, r.Systems, 3)
+		names := []string{r.Systems[0].Name, r.Systems[1].Name, r.Systems[2].Name}
+		assert.Equal(t, []string{"move", "regen", "*simulation.countingSystem"}, names)
+		for _, s := range r.Systems {
+			assert.Equal(t, 3, s.Global.Samples, "workers=%d system=%s", workers, s.Name)
+			assert.Equal(t, 3, s.Entity.Samples, "workers=%d system=%s", workers, s.Name)
+		}
+		assert.Equal(t, 100, r.Systems[0].LastEntities)
+		assert.Equal(t, 100, r.Systems[1].LastEntities)
+		assert.Equal(t, 101, r.Systems[2].LastEntities)
+	}
+}
+
+// sleepSystem takes a fixed time per entity.
+type sleepSystem struct{}
+
+func (sleepSystem) Requires() []ecs.ComponentType { return []ecs.ComponentType{healthType} }
+
+func (sleepSystem) UpdateSimulation(any) error { return nil }
+
+func (sleepSystem) UpdateEntitySimulation(any, *ecs.Entity) error {
+	time.Sleep(5 * time.Millisecond)
+	return nil
+}
+
+func (sleepSystem) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{Reads: []ecs.ComponentType{healthType}}
+}
+
+func TestProfilingRecordsParallelWallTime(t *testing.T) {
+	w := buildWorld(8)
+	m := &SimulationSystemManager{}
+	m.AddSystem(sleepSystem{})
+	m.SetWorkers(8)
+	p := ecs.NewProfiler(0)
+	m.SetProfiler(p)
+
+	assert.Nil(t, m.UpdateSystemsForWorld(w, w))
+	r := p.Report()
+	assert.Equal(t, 8, r.Systems[0].LastEntities)
+	assert.Less(t, r.Systems[0].Entity.Last, 30*time.Millisecond, "Expected wall time, not 40ms summed across workers")
+}
+
+func TestDisabledAndThrottledSystemsInParallelStages(t *testing.T) {
+	w := buildWorld(50)
+	m := &SimulationSystemManager{}
+	move := &moveSystem{}
+	m.AddSystem(move)
+	regen := &regenSystem{}
+	m.AddSystemWithOptions(regen, ecs.SystemOptions{Name: "regen", Writes: []ecs.ComponentType{healthType}, Every: 2})
+	m.SetWorkers(4)
+
+	assert.Nil(t, m.SetEnabled("move", false))
+	runTicks(t, m, w, 4, true)
+	assert.Equal(t, int32(0), move.globalCalls.Load())
+	assert.Equal(t, int32(2), regen.globalCalls.Load())
+	for _, e := range w.Entities() {
+		assert.Equal(t, position{}, ecs.Get[position](e), "Expected disabled system not to run per entity")
+	}
+	first := w.Entities()[0]
+	assert.Equal(t, health{HP: 2}, ecs.Get[health](first), "Expected throttled system to run every other tick")
+}
+
+// TestParallelStructuralChanges adds a component type from a parallel stage
+// while queries, observers and forwarded events watch it. Run with -race.
+func TestParallelStructuralChanges(t *testing.T) {
+	w := ecs.NewWorld()
+	for i := 0; i < 1000; i++ {
+		e := w.Spawn()
+		e.AddComponent(position{})
+		e.AddComponent(health{})
+	}
+	added, changed := 0, 0
+	w.OnAdded(velocityType, func(*ecs.Entity, ecs.Component) { added++ })
+	w.OnChanged(healthType, func(*ecs.Entity, ecs.Component) { changed++ })
+	events := &event.QueuedEventManager{}
+	w.ForwardComponentEvents(velocityType, events.QueueEvent)
+
+	m := &SimulationSystemManager{}
+	m.AddSystem(launchSystem{})
+	m.AddSystem(&regenSystem{})
+	move := &moveSystem{}
+	m.AddSystem(move)
+	m.SetWorkers(4)
+
+	assert.Nil(t, m.UpdateSystemsForWorld(w, w))
+	assert.Equal(t, 1000, w.Query(velocityType).Len())
+	assert.Equal(t, 1000, added)
+	assert.Equal(t, 1000, changed)
+	for _, e := range w.Entities() {
+		assert.Equal(t, position{X: 1}, ecs.Get[position](e), "Expected later stages to see the added components")
+		assert.Equal(t, health{HP: 1}, ecs.Get[health](e))
+	}
+}
+
+// globalWriter writes one component back on the entities of the world from
+// its global UpdateSimulation, the way systems that keep their own entity
+// lists do.
+type globalWriter struct {
+	name   string
+	writes ecs.ComponentType
+	update func([]*ecs.Entity)
+}
+
+func (s *globalWriter) Requires() []ecs.ComponentType { return nil }
+
+func (s *globalWriter) UpdateSimulation(world any) error {
+	s.update(world.(*ecs.World).Entities())
+	return nil
+}
+
+func (s *globalWriter) UpdateEntitySimulation(any, *ecs.Entity) error { return nil }
+
+func (s *globalWriter) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{Name: s.name, Writes: []ecs.ComponentType{s.writes}}
+}
+
+// TestParallelGlobalWriteBacks has two non-conflicting systems write back
+// different components of the same entities from a parallel global stage.
+// heal reads every entity before writing any, so the race detector sees its
+// reads overlap push's writes if those reach the entities directly. Run with
+// -race.
+func TestParallelGlobalWriteBacks(t *testing.T) {
+	w := ecs.NewWorld()
+	for i := 0; i < 1000; i++ {
+		e := w.Spawn()
+		e.AddComponent(position{})
+		e.AddComponent(health{})
+	}
+	var changed []health
+	w.OnChanged(healthType, func(_ *ecs.Entity, c ecs.Component) { changed = append(changed, c.(health)) })
+
+	m := &SimulationSystemManager{}
+	m.AddSystem(&globalWriter{name: "push", writes: positionType, update: func(es []*ecs.Entity) {
+		for _, e := range es {
+			ecs.Mutate(e, func(p *position) { p.X++ })
+		}
+	}})
+	m.AddSystem(&globalWriter{name: "heal", writes: healthType, update: func(es []*ecs.Entity) {
+		hp := make([]int, len(es))
+		for i, e := range es {
+			hp[i] = ecs.Get[health](e).HP
+		}
+		for i, e := range es {
+			e.AddComponent(health{HP: hp[i] + 1})
+			e.MarkChanged(healthType)
+		}
+	}})
+	m.SetWorkers(4)
+	stages, err := m.schedule.Stages()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{0, 1}}, stages)
+
+	for tick := 1; tick <= 10; tick++ {
+		changed = changed[:0]
+		assert.Nil(t, m.UpdateSystems(w))
+		assert.Len(t, changed, 2000, "Expected replacements and MarkChanged notified when the stage ends")
+		for _, c := range changed {
+			assert.Equal(t, health{HP: tick}, c, "Expected notifications to carry the applied component")
+		}
+	}
+	for _, e := range w.Entities() {
+		assert.Equal(t, position{X: 10}, ecs.Get[position](e))
+		assert.Equal(t, health{HP: 10}, ecs.Get[health](e))
+	}
+}
+
+var stunned = ecs.NewTag("stunned")
+
+// stunSystem toggles a tag on every entity it visits, so parallel batches
+// update the world's tag index together. Run with -race.
+type stunSystem struct{}
+
+func (stunSystem) Requires() []ecs.ComponentType { return []ecs.ComponentType{healthType} }
+
+func (stunSystem) UpdateSimulation(any) error { return nil }
+
+func (stunSystem) UpdateEntitySimulation(_ any, e *ecs.Entity) error {
+	if e.HasTag(stunned) {
+		e.ClearTag(stunned)
+	} else {
+		e.SetTag(stunned)
+	}
+	return nil
+}
+
+func (stunSystem) SystemOptions() ecs.SystemOptions {
+	return ecs.SystemOptions{Name: "stun", Writes: []ecs.ComponentType{healthType}}
+}
+
+func TestParallelTagChanges(t *testing.T) {
+	w := ecs.NewWorld()
+	for i := 0; i < 1000; i++ {
+		w.Spawn().AddComponent(health{})
+	}
+	m := &SimulationSystemManager{}
+	m.AddSystem(stunSystem{})
+	m.SetWorkers(4)
+
+	assert.Nil(t, m.UpdateSystemsForWorld(w, w))
+	assert.Len(t, w.Tagged(stunned), 1000)
+	assert.Nil(t, m.UpdateSystemsForWorld(w, w))
+	assert.Empty(t, w.Tagged(stunned), "Expected the tag seen by the stage to be cleared")
+}
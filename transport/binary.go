@@ -0,0 +1,681 @@
+package transport
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mechanical-lich/mlge/ecs"
+)
+
+// BinaryCodec is a compact [WireCodec]. Numbers are written as varints or
+// fixed-width floats and every repeated string in a message (component
+// types, blueprints, type names) is sent once and then referenced by index,
+// so snapshots are a fraction of their JSON size.
+//
+// Values stored in interface-typed fields - [Command.Payload],
+// [EntitySnapshot.Components] and any interface fields inside them - are
+// sent with their type name and decode to the same concrete type, as long as
+// that type is registered on both ends with Register or RegisterName, in the
+// manner of encoding/gob. Basic types, []byte, []any, map[string]any and
+// [InputPayload] are registered by NewBinaryCodec.
+//
+// Struct values encode their exported fields in declaration order, skipping
+// fields tagged `json:"-"`, so both ends must use the same struct
+// definitions. Funcs, channels and complex numbers cannot be encoded.
+//
+// Register every type before the codec is used; it is safe for concurrent use
+// afterwards.
+//
+//	codec := transport.NewBinaryCodec()
+//	codec.Register(PositionComponent{})
+//	codec.Register(MovePayload{})
+//	srvT, err := transport.NewTCPServerTransportWithCodec(":7777", codec)
+type BinaryCodec struct {
+	mu    sync.RWMutex
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+const (
+	binaryKindCommand  byte = 1
+	binaryKindSnapshot byte = 2
+
+	// binaryMaxDepth bounds nesting so cyclic values fail to encode and
+	// hostile messages cannot exhaust the stack.
+	binaryMaxDepth = 64
+)
+
+var errBinaryTruncated = errors.New("transport: binary message truncated")
+
+// NewBinaryCodec returns a BinaryCodec with the basic types registered.
+func NewBinaryCodec() *BinaryCodec {
+	c := &BinaryCodec{
+		names: make(map[reflect.Type]string),
+		types: make(map[string]reflect.Type),
+	}
+	for _, v := range []any{
+		false, "",
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0),
+		[]byte(nil), []any(nil), map[string]any(nil),
+		InputPayload{},
+	} {
+		c.Register(v)
+	}
+	return c
+}
+
+// Register records the concrete type of value under its type string, e.g.
+// "game.PositionComponent" or "*game.PositionComponent".
+func (c *BinaryCodec) Register(value any) {
+	c.RegisterName(reflect.TypeOf(value).String(), value)
+}
+
+// RegisterName records the concrete type of value under name. Like
+// gob.RegisterName it panics if either is already registered to something
+// else.
+func (c *BinaryCodec) RegisterName(name string, value any) {
+	t := reflect.TypeOf(value)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if other, ok := c.types[name]; ok && other != t {
+		panic(fmt.Sprintf("transport: registering duplicate types for %q: %s != %s", name, other, t))
+	}
+	if other, ok := c.names[t]; ok && other != name {
+		panic(fmt.Sprintf("transport: registering duplicate names for %s: %q != %q", t, other, name))
+	}
+	c.types[name] = t
+	c.names[t] = name
+}
+
+// EncodeCommand encodes cmd.
+func (c *BinaryCodec) EncodeCommand(cmd *Command) ([]byte, error) {
+	e := &binaryEncoder{c: c, buf: []byte{binaryKindCommand}}
+	e.string(string(cmd.Type))
+	e.uvarint(cmd.Tick)
+	e.uvarint(uint64(cmd.ClientID))
+	if err := e.any(cmd.Payload); err != nil {
+		return nil, fmt.Errorf("transport: encode %s payload: %w", cmd.Type, err)
+	}
+	return e.buf, nil
+}
+
+// EncodeSnapshot encodes snapshot.
+func (c *BinaryCodec) EncodeSnapshot(snapshot *Snapshot) ([]byte, error) {
+	e := &binaryEncoder{c: c, buf: []byte{binaryKindSnapshot}}
+	e.uvarint(snapshot.Tick)
+	e.varint(snapshot.Timestamp)
+	e.uvarint(snapshot.Baseline)
+	e.length(len(snapshot.Entities), snapshot.Entities == nil)
+	for _, es := range snapshot.Entities {
+		e.string(es.ID)
+		e.string(es.Blueprint)
+		e.length(len(es.Components), es.Components == nil)
+		for t, v := range es.Components {
+			e.string(string(t))
+			if err := e.any(v); err != nil {
+				return nil, fmt.Errorf("transport: encode entity %s component %s: %w", es.ID, t, err)
+			}
+		}
+		e.uvarint(uint64(len(es.Removed)))
+		for _, t := range es.Removed {
+			e.string(string(t))
+		}
+	}
+	e.uvarint(uint64(len(snapshot.Removed)))
+	for _, id := range snapshot.Removed {
+		e.string(id)
+	}
+	e.uvarint(uint64(len(snapshot.ClientTicks)))
+	for id, tick := range snapshot.ClientTicks {
+		e.uvarint(uint64(id))
+		e.uvarint(tick)
+	}
+	return e.buf, nil
+}
+
+// Decode decodes a message written by EncodeCommand or EncodeSnapshot.
+func (c *BinaryCodec) Decode(data []byte) (*Command, *Snapshot, error) {
+	if len(data) == 0 {
+		return nil, nil, errBinaryTruncated
+	}
+	d := &binaryDecoder{c: c, data: data[1:]}
+	switch data[0] {
+	case binaryKindCommand:
+		cmd, err := d.command()
+		return cmd, nil, err
+	case binaryKindSnapshot:
+		snap, err := d.snapshot()
+		return nil, snap, err
+	}
+	return nil, nil, fmt.Errorf("transport: unknown binary message kind %d", data[0])
+}
+
+// binaryFields caches the encoded field indices of struct types.
+var binaryFields sync.Map // reflect.Type → []int
+
+func structFields(t reflect.Type) []int {
+	if f, ok := binaryFields.Load(t); ok {
+		return f.([]int)
+	}
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if tag, _, _ := strings.Cut(sf.Tag.Get("json"), ","); tag == "-" {
+			continue
+		}
+		fields = append(fields, i)
+	}
+	binaryFields.Store(t, fields)
+	return fields
+}
+
+// --- encoding ---
+
+type binaryEncoder struct {
+	c     *BinaryCodec
+	buf   []byte
+	table map[string]uint64 // strings written so far, by index
+	depth int
+}
+
+func (e *binaryEncoder) uvarint(v uint64) { e.buf = binary.AppendUvarint(e.buf, v) }
+func (e *binaryEncoder) varint(v int64)   { e.buf = binary.AppendVarint(e.buf, v) }
+
+// length writes a slice or map length, distinguishing nil from empty.
+func (e *binaryEncoder) length(n int, isNil bool) {
+	if isNil {
+		e.uvarint(0)
+		return
+	}
+	e.uvarint(uint64(n) + 1)
+}
+
+// string writes s, or a reference to its earlier occurrence in the message.
+// The low bit tells the two apart.
+func (e *binaryEncoder) string(s string) {
+	if i, ok := e.table[s]; ok {
+		e.uvarint(i<<1 | 1)
+		return
+	}
+	if e.table == nil {
+		e.table = make(map[string]uint64)
+	}
+	e.table[s] = uint64(len(e.table))
+	e.uvarint(uint64(len(s)) << 1)
+	e.buf = append(e.buf, s...)
+}
+
+// any writes v's registered type name followed by its value.
+func (e *binaryEncoder) any(v any) error {
+	if v == nil {
+		e.buf = append(e.buf, 0)
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	e.c.mu.RLock()
+	name, ok := e.c.names[rv.Type()]
+	e.c.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("type %s is not registered with the BinaryCodec", rv.Type())
+	}
+	e.buf = append(e.buf, 1)
+	e.string(name)
+	return e.value(rv)
+}
+
+func (e *binaryEncoder) value(v reflect.Value) error {
+	if e.depth++; e.depth > binaryMaxDepth {
+		return fmt.Errorf("%s nested too deeply", v.Type())
+	}
+	defer func() { e.depth-- }()
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.varint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.uvarint(v.Uint())
+	case reflect.Float32:
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Slice:
+		e.length(v.Len(), v.IsNil())
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.buf = append(e.buf, v.Bytes()...)
+			return nil
+		}
+		return e.elements(v)
+	case reflect.Array:
+		return e.elements(v)
+	case reflect.Map:
+		e.length(v.Len(), v.IsNil())
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := e.value(iter.Key()); err != nil {
+				return err
+			}
+			if err := e.value(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for _, i := range structFields(v.Type()) {
+			if err := e.value(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0)
+			return nil
+		}
+		e.buf = append(e.buf, 1)
+		return e.value(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return e.any(nil)
+		}
+		return e.any(v.Elem().Interface())
+	default:
+		return fmt.Errorf("cannot encode %s values", v.Type())
+	}
+	return nil
+}
+
+func (e *binaryEncoder) elements(v reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := e.value(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- decoding ---
+
+type binaryDecoder struct {
+	c     *BinaryCodec
+	data  []byte
+	table []string // strings read so far, by index
+	depth int
+}
+
+func (d *binaryDecoder) command() (*Command, error) {
+	typ, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+	cmd := &Command{Type: CommandType(typ)}
+	if cmd.Tick, err = d.uvarint(); err != nil {
+		return nil, err
+	}
+	id, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	cmd.ClientID = ClientID(id)
+	if cmd.Payload, err = d.any(); err != nil {
+		return nil, fmt.Errorf("transport: decode %s payload: %w", cmd.Type, err)
+	}
+	return cmd, nil
+}
+
+func (d *binaryDecoder) snapshot() (*Snapshot, error) {
+	snap := &Snapshot{}
+	var err error
+	if snap.Tick, err = d.uvarint(); err != nil {
+		return nil, err
+	}
+	if snap.Timestamp, err = d.varint(); err != nil {
+		return nil, err
+	}
+	if snap.Baseline, err = d.uvarint(); err != nil {
+		return nil, err
+	}
+	n, isNil, err := d.length(1)
+	if err != nil {
+		return nil, err
+	}
+	if !isNil {
+		snap.Entities = make([]*EntitySnapshot, n)
+	}
+	for i := range snap.Entities {
+		es := &EntitySnapshot{}
+		if es.ID, err = d.string(); err != nil {
+			return nil, err
+		}
+		if es.Blueprint, err = d.string(); err != nil {
+			return nil, err
+		}
+		comps, isNil, err := d.length(2)
+		if err != nil {
+			return nil, err
+		}
+		if !isNil {
+			es.Components = make(map[ecs.ComponentType]ComponentData, comps)
+		}
+		for j := 0; j < comps; j++ {
+			t, err := d.string()
+			if err != nil {
+				return nil, err
+			}
+			if es.Components[ecs.ComponentType(t)], err = d.any(); err != nil {
+				return nil, fmt.Errorf("transport: decode entity %s component %s: %w", es.ID, t, err)
+			}
+		}
+		removed, err := d.strings()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range removed {
+			es.Removed = append(es.Removed, ecs.ComponentType(t))
+		}
+		snap.Entities[i] = es
+	}
+	if snap.Removed, err = d.strings(); err != nil {
+		return nil, err
+	}
+	ticks, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if ticks > uint64(len(d.data)/2) {
+		return nil, errBinaryTruncated
+	}
+	if ticks > 0 {
+		snap.ClientTicks = make(map[ClientID]uint64, ticks)
+	}
+	for i := uint64(0); i < ticks; i++ {
+		id, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if snap.ClientTicks[ClientID(id)], err = d.uvarint(); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+func (d *binaryDecoder) byte() (byte, error) {
+	if len(d.data) == 0 {
+		return 0, errBinaryTruncated
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b, nil
+}
+
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, errBinaryTruncated
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+func (d *binaryDecoder) varint() (int64, error) {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		return 0, errBinaryTruncated
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+// length reads a length written by binaryEncoder.length. Each element takes
+// at least minSize bytes, which bounds the length by what is left of the
+// message; zero-size elements are bounded by maxMsgSize.
+func (d *binaryDecoder) length(minSize int) (n int, isNil bool, err error) {
+	v, err := d.uvarint()
+	if err != nil {
+		return 0, false, err
+	}
+	if v == 0 {
+		return 0, true, nil
+	}
+	limit := uint64(maxMsgSize)
+	if minSize > 0 {
+		limit = uint64(len(d.data) / minSize)
+	}
+	if v-1 > limit {
+		return 0, false, errBinaryTruncated
+	}
+	return int(v - 1), false, nil
+}
+
+func (d *binaryDecoder) string() (string, error) {
+	v, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if v&1 == 1 {
+		if v>>1 >= uint64(len(d.table)) {
+			return "", fmt.Errorf("transport: binary string reference %d out of range", v>>1)
+		}
+		return d.table[v>>1], nil
+	}
+	if v>>1 > uint64(len(d.data)) {
+		return "", errBinaryTruncated
+	}
+	s := string(d.data[:v>>1])
+	d.data = d.data[v>>1:]
+	d.table = append(d.table, s)
+	return s, nil
+}
+
+// strings reads a list of strings, returning nil when it is empty.
+func (d *binaryDecoder) strings() ([]string, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.data)) {
+		return nil, errBinaryTruncated
+	}
+	var list []string
+	for i := uint64(0); i < n; i++ {
+		s, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// any reads a value written by binaryEncoder.any.
+func (d *binaryDecoder) any() (any, error) {
+	present, err := d.byte()
+	if err != nil || present == 0 {
+		return nil, err
+	}
+	name, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+	d.c.mu.RLock()
+	t, ok := d.c.types[name]
+	d.c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("type %q is not registered with the BinaryCodec", name)
+	}
+	v := reflect.New(t).Elem()
+	if err := d.value(v); err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func (d *binaryDecoder) value(v reflect.Value) error {
+	if d.depth++; d.depth > binaryMaxDepth {
+		return fmt.Errorf("%s nested too deeply", v.Type())
+	}
+	defer func() { d.depth-- }()
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := d.byte()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := d.varint()
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := d.uvarint()
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32:
+		if len(d.data) < 4 {
+			return errBinaryTruncated
+		}
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(d.data))))
+		d.data = d.data[4:]
+	case reflect.Float64:
+		if len(d.data) < 8 {
+			return errBinaryTruncated
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(d.data)))
+		d.data = d.data[8:]
+	case reflect.String:
+		s, err := d.string()
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		n, isNil, err := d.length(minEncodedSize(v.Type().Elem()))
+		if err != nil || isNil {
+			return err
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte{}, d.data[:n]...))
+			d.data = d.data[n:]
+			return nil
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		return d.elements(v)
+	case reflect.Array:
+		return d.elements(v)
+	case reflect.Map:
+		n, isNil, err := d.length(minEncodedSize(v.Type().Key()) + minEncodedSize(v.Type().Elem()))
+		if err != nil || isNil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := d.value(key); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		for _, i := range structFields(v.Type()) {
+			if err := d.value(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Pointer:
+		present, err := d.byte()
+		if err != nil || present == 0 {
+			return err
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		return d.value(v.Elem())
+	case reflect.Interface:
+		x, err := d.any()
+		if err != nil || x == nil {
+			return err
+		}
+		xv := reflect.ValueOf(x)
+		if !xv.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("%s does not implement %s", xv.Type(), v.Type())
+		}
+		v.Set(xv)
+	default:
+		return fmt.Errorf("cannot decode %s values", v.Type())
+	}
+	return nil
+}
+
+func (d *binaryDecoder) elements(v reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := d.value(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// minEncodedSize is the fewest bytes a value of type t encodes to, but at
+// least one for any type that takes memory. Bounding lengths by it keeps a
+// short message from making the decoder allocate a huge slice or map.
+func minEncodedSize(t reflect.Type) int {
+	if n, ok := binaryMinSizes.Load(t); ok {
+		return n.(int)
+	}
+	n := encodedFloor(t)
+	if n == 0 && t.Size() > 0 {
+		n = 1
+	}
+	binaryMinSizes.Store(t, n)
+	return n
+}
+
+// binaryMinSizes caches minEncodedSize per type.
+var binaryMinSizes sync.Map // reflect.Type → int
+
+// encodedFloor is the fewest bytes a value of type t encodes to, capped at
+// maxMsgSize.
+func encodedFloor(t reflect.Type) int {
+	switch t.Kind() {
+	case reflect.Struct:
+		n := 0
+		for _, i := range structFields(t) {
+			n = min(n+encodedFloor(t.Field(i).Type), maxMsgSize)
+		}
+		return n
+	case reflect.Array:
+		if t.Len() == 0 {
+			return 0
+		}
+		return min(encodedFloor(t.Elem()), maxMsgSize/t.Len()) * t.Len()
+	case reflect.Float32:
+		return 4
+	case reflect.Float64:
+		return 8
+	}
+	return 1
+}
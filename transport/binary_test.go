@@ -0,0 +1,191 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type wirePosition struct{ X, Y float64 }
+
+type wireStats struct {
+	Health  int
+	Name    string
+	Flags   map[string]bool
+	Loot    []string
+	Target  *wirePosition
+	Extra   any
+	OnDeath func() `json:"-"`
+	hidden  int
+}
+
+type wireMove struct {
+	Dir   ecs.EntityID
+	Steps uint8
+}
+
+func newTestBinaryCodec() *BinaryCodec {
+	c := NewBinaryCodec()
+	c.Register(wirePosition{})
+	c.Register(&wireStats{})
+	c.Register(wireMove{})
+	return c
+}
+
+func testWorldSnapshot(n int) *Snapshot {
+	entities := make([]*EntitySnapshot, n)
+	for i := range entities {
+		entities[i] = &EntitySnapshot{
+			ID:        ecs.EntityID(i + 1).String(),
+			Blueprint: "goblin",
+			Components: map[ecs.ComponentType]ComponentData{
+				"Position": wirePosition{X: float64(i) + 0.5, Y: 3.25},
+				"Stats":    &wireStats{Health: 30, Name: "grunt", Loot: []string{"gold"}},
+			},
+		}
+	}
+	return &Snapshot{Tick: 9, Timestamp: 1700000000000000000, Entities: entities}
+}
+
+func TestBinaryCodecRoundTripsCommands(t *testing.T) {
+	c := newTestBinaryCodec()
+	for _, cmd := range []*Command{
+		{Type: "move", Tick: 12, ClientID: 3, Payload: wireMove{Dir: 77, Steps: 2}},
+		{Type: CommandInput, Payload: InputPayload{EventType: "MouseLeftClick", Data: map[string]any{"X": 1.5, "Y": []any{"a", true}}}},
+		{Type: CommandSnapshotAck, Tick: 4},
+	} {
+		data, err := c.EncodeCommand(cmd)
+		require.NoError(t, err)
+		got, snap, err := c.Decode(data)
+		require.NoError(t, err)
+		assert.Nil(t, snap)
+		assert.Equal(t, cmd, got)
+	}
+}
+
+func TestBinaryCodecRoundTripsSnapshots(t *testing.T) {
+	c := newTestBinaryCodec()
+	full := testWorldSnapshot(3)
+	stats := full.Entities[0].Components["Stats"].(*wireStats)
+	stats.Flags = map[string]bool{"angry": true}
+	stats.Target = &wirePosition{X: 1}
+	stats.Extra = wirePosition{Y: 2}
+
+	delta := DiffSnapshots(testWorldSnapshot(4), full)
+	delta.Entities = append(delta.Entities, &EntitySnapshot{ID: "x", Removed: []ecs.ComponentType{"Burning"}})
+
+	full.ClientTicks = map[ClientID]uint64{1: 40, 7: 3}
+
+	for _, want := range []*Snapshot{full, delta, {Tick: 1}} {
+		data, err := c.EncodeSnapshot(want)
+		require.NoError(t, err)
+		cmd, got, err := c.Decode(data)
+		require.NoError(t, err)
+		assert.Nil(t, cmd)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestBinaryCodecRejectsUnknownTypes(t *testing.T) {
+	c := NewBinaryCodec()
+	_, err := c.EncodeCommand(&Command{Type: "move", Payload: wireMove{}})
+	assert.ErrorContains(t, err, "not registered")
+
+	data, err := newTestBinaryCodec().EncodeCommand(&Command{Type: "move", Payload: wireMove{}})
+	require.NoError(t, err)
+	_, _, err = c.Decode(data)
+	assert.ErrorContains(t, err, "not registered")
+
+	for i := range data {
+		_, _, err := c.Decode(data[:i])
+		assert.Error(t, err, "Expected truncated messages to fail")
+	}
+
+	assert.Panics(t, func() { c.RegisterName("int", "") })
+}
+
+type wireVolley struct {
+	Shots []struct{ A, B, C, D float64 }
+	Skip  []struct{ f func() }
+}
+
+func TestBinaryCodecBoundsLengthsByMessageSize(t *testing.T) {
+	c := NewBinaryCodec()
+	c.Register(wireVolley{})
+	v := wireVolley{Shots: make([]struct{ A, B, C, D float64 }, 100)}
+	data, err := c.EncodeCommand(&Command{Type: "fire", Payload: v})
+	require.NoError(t, err)
+	// The 100 zero shots are the tail after the Skip slice's nil marker;
+	// claim millions of shots without sending them.
+	prefix := data[:len(data)-100*32-1]
+	require.Equal(t, byte(101), prefix[len(prefix)-1])
+	forged := binary.AppendUvarint(bytes.Clone(prefix[:len(prefix)-1]), 4<<20)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, _, err = c.Decode(forged)
+	runtime.ReadMemStats(&after)
+	assert.Error(t, err)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "Expected no allocation for elements the message cannot hold")
+
+	skipped := append(bytes.Clone(data[:len(data)-1]), 101)
+	_, _, err = c.Decode(skipped)
+	assert.Error(t, err, "Expected elements that encode to nothing to count as a byte each")
+}
+
+func TestBinaryCodecIsSmallerThanJSON(t *testing.T) {
+	snap := testWorldSnapshot(100)
+	bin, err := newTestBinaryCodec().EncodeSnapshot(snap)
+	require.NoError(t, err)
+	js, err := JSONCodec{}.EncodeSnapshot(snap)
+	require.NoError(t, err)
+	t.Logf("binary %d bytes, JSON %d bytes", len(bin), len(js))
+	assert.Less(t, len(bin)*3, len(js), "Expected the binary snapshot to be under a third of the JSON size")
+}
+
+func TestTCPTransportWithBinaryCodec(t *testing.T) {
+	c := newTestBinaryCodec()
+	srv, err := NewTCPServerTransportWithCodec("127.0.0.1:0", c)
+	require.NoError(t, err)
+	defer srv.Close()
+	cli, err := NewTCPClientTransportWithCodec(srv.(*TCPServerTransport).listener.Addr().String(), c)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	cli.SendCommand(&Command{Type: "move", Payload: wireMove{Dir: 5, Steps: 1}})
+	cmds := receiveUntil(srv, 2)
+	require.Len(t, cmds, 2)
+	assert.Equal(t, wireMove{Dir: 5, Steps: 1}, cmds[1].Payload, "Expected concrete payload types to survive the wire")
+
+	want := testWorldSnapshot(2)
+	srv.SendSnapshot(want)
+	var got *Snapshot
+	for i := 0; i < 1000 && got == nil; i++ {
+		got = cli.ReceiveSnapshot()
+		if got == nil {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	require.NotNil(t, got)
+	assert.Equal(t, want, got)
+}
+
+func BenchmarkEncodeSnapshot(b *testing.B) {
+	snap := testWorldSnapshot(100)
+	for _, codec := range []WireCodec{JSONCodec{}, newTestBinaryCodec()} {
+		b.Run(fmt.Sprintf("%T", codec), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				data, _ := codec.EncodeSnapshot(snap)
+				b.SetBytes(int64(len(data)))
+			}
+		})
+	}
+}
@@ -1,26 +1,51 @@
 package transport
 
+import "sync"
+
 // CommandType identifies the kind of command being sent from client to server.
 // Games should define their own CommandType constants (e.g., "move", "build", "attack").
-// Built-in types are prefixed with "input." to avoid name collisions.
+// Built-in types are prefixed with "input." or "client." to avoid name collisions.
 type CommandType string
 
 const (
 	// CommandInput carries raw input events translated from mlge's input package.
 	// The Payload will be an [InputPayload].
 	CommandInput CommandType = "input.raw"
+
+	// CommandClientConnected is queued by a ServerTransport when a client
+	// connects, before any command from that client. It has no Payload.
+	CommandClientConnected CommandType = "client.connected"
+
+	// CommandClientDisconnected is queued by a ServerTransport when a client
+	// disconnects, after every command from that client. It has no Payload.
+	CommandClientDisconnected CommandType = "client.disconnected"
+
+	// CommandSnapshotAck is sent by a [DeltaClientTransport] for every
+	// snapshot it reconstructs; Tick is the snapshot's tick. A
+	// [DeltaServerTransport] consumes it. It has no Payload.
+	CommandSnapshotAck CommandType = "client.ack"
 )
 
+// ClientID identifies one client connection to a ServerTransport. The server
+// transport assigns IDs as clients connect, starting at 1, and does not reuse
+// them while it is open. The zero value means no client.
+type ClientID uint32
+
 // Command is a timestamped, typed message sent from the client to the server.
 // It represents player intent: a key press, a mouse click, a game action.
 //
-// Tick is the client-estimated server tick this command targets.
-// For Phase 1 (local transport, no prediction) this is informational.
-// In a future TCP implementation it enables client-side prediction reconciliation.
+// Tick is the client's prediction tick: a client.Predictor stamps each
+// command with the local tick it is predicted on, and the server reports back
+// in [Snapshot.ClientTicks] how far it has got, so the client can reconcile.
+// Zero for commands that are not predicted.
+//
+// ClientID is set by the ServerTransport to the connection the command
+// arrived on; any value the client sends is overwritten.
 type Command struct {
-	Type    CommandType
-	Tick    uint64
-	Payload any
+	Type     CommandType
+	Tick     uint64
+	ClientID ClientID
+	Payload  any
 }
 
 // InputPayload carries a translated input event as a command payload.
@@ -36,3 +61,40 @@ type InputPayload struct {
 	// A future network transport would serialize this to JSON/proto before sending.
 	Data any
 }
+
+// isConnectionCommand reports whether t is one of the connection
+// notifications only a ServerTransport may queue.
+func isConnectionCommand(t CommandType) bool {
+	return t == CommandClientConnected || t == CommandClientDisconnected
+}
+
+// commandQueue is the ordered queue a ServerTransport drains in
+// ReceiveCommands. Client commands are dropped once limit are pending;
+// connect and disconnect notifications are never dropped, so they stay
+// ordered with the commands around them.
+type commandQueue struct {
+	mu    sync.Mutex
+	cmds  []*Command
+	limit int
+}
+
+func (q *commandQueue) push(cmd *Command) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.cmds) >= q.limit && !isConnectionCommand(cmd.Type) {
+		return // server stalled; drop the command
+	}
+	q.cmds = append(q.cmds, cmd)
+}
+
+// drain returns and clears the pending commands, never nil.
+func (q *commandQueue) drain() []*Command {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	cmds := q.cmds
+	q.cmds = nil
+	if cmds == nil {
+		cmds = []*Command{}
+	}
+	return cmds
+}
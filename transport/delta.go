@@ -0,0 +1,330 @@
+package transport
+
+import (
+	"log"
+	"reflect"
+	"slices"
+
+	"github.com/mechanical-lich/mlge/ecs"
+)
+
+// deltaHistory is how many recent snapshots both delta wrappers keep per
+// client as possible baselines. A client whose last acknowledged snapshot has fallen
+// out of the server's history gets a full snapshot instead.
+const deltaHistory = 32
+
+// DiffSnapshots returns a delta snapshot holding what changed from baseline
+// to current: entities that were created, the changed components of entities
+// that changed (compared with reflect.DeepEqual), and the IDs of entities and
+// components that were removed. Unchanged entities are omitted.
+//
+// The SnapshotCodec must encode component values, not pointers to live
+// components, or changes cannot be detected.
+func DiffSnapshots(baseline, current *Snapshot) *Snapshot {
+	delta := &Snapshot{
+		Tick:        current.Tick,
+		Timestamp:   current.Timestamp,
+		Baseline:    baseline.Tick,
+		ClientTicks: current.ClientTicks,
+	}
+	prev := make(map[string]*EntitySnapshot, len(baseline.Entities))
+	for _, es := range baseline.Entities {
+		prev[es.ID] = es
+	}
+	for _, es := range current.Entities {
+		old, ok := prev[es.ID]
+		if !ok {
+			delta.Entities = append(delta.Entities, es)
+			continue
+		}
+		delete(prev, es.ID)
+		if d := diffEntity(old, es); d != nil {
+			delta.Entities = append(delta.Entities, d)
+		}
+	}
+	for _, es := range baseline.Entities {
+		if _, ok := prev[es.ID]; ok {
+			delta.Removed = append(delta.Removed, es.ID)
+		}
+	}
+	return delta
+}
+
+// diffEntity returns the changes from old to cur, or nil if there are none.
+func diffEntity(old, cur *EntitySnapshot) *EntitySnapshot {
+	d := &EntitySnapshot{ID: cur.ID, Blueprint: cur.Blueprint}
+	for t, v := range cur.Components {
+		if ov, ok := old.Components[t]; !ok || !reflect.DeepEqual(ov, v) {
+			if d.Components == nil {
+				d.Components = make(map[ecs.ComponentType]ComponentData)
+			}
+			d.Components[t] = v
+		}
+	}
+	for t := range old.Components {
+		if _, ok := cur.Components[t]; !ok {
+			d.Removed = append(d.Removed, t)
+		}
+	}
+	if d.Components == nil && d.Removed == nil && old.Blueprint == cur.Blueprint {
+		return nil
+	}
+	slices.Sort(d.Removed)
+	return d
+}
+
+// ApplyDelta reconstructs the full snapshot a delta was diffed from, given
+// the same baseline. Entities keep the baseline's order, followed by the
+// entities the delta created. Unchanged entity snapshots are shared with
+// baseline, so neither snapshot may be modified afterwards.
+func ApplyDelta(baseline, delta *Snapshot) *Snapshot {
+	full := &Snapshot{
+		Tick:        delta.Tick,
+		Timestamp:   delta.Timestamp,
+		Entities:    make([]*EntitySnapshot, 0, len(baseline.Entities)+len(delta.Entities)),
+		ClientTicks: delta.ClientTicks,
+	}
+	changed := make(map[string]*EntitySnapshot, len(delta.Entities))
+	for _, es := range delta.Entities {
+		changed[es.ID] = es
+	}
+	removed := make(map[string]bool, len(delta.Removed))
+	for _, id := range delta.Removed {
+		removed[id] = true
+	}
+	for _, es := range baseline.Entities {
+		if removed[es.ID] {
+			continue
+		}
+		if d, ok := changed[es.ID]; ok {
+			delete(changed, es.ID)
+			es = mergeEntity(es, d)
+		}
+		full.Entities = append(full.Entities, es)
+	}
+	for _, es := range delta.Entities {
+		if _, ok := changed[es.ID]; ok {
+			full.Entities = append(full.Entities, es)
+		}
+	}
+	return full
+}
+
+func mergeEntity(old, d *EntitySnapshot) *EntitySnapshot {
+	es := &EntitySnapshot{
+		ID:         d.ID,
+		Blueprint:  d.Blueprint,
+		Components: make(map[ecs.ComponentType]ComponentData, len(old.Components)+len(d.Components)),
+	}
+	for t, v := range old.Components {
+		if !slices.Contains(d.Removed, t) {
+			es.Components[t] = v
+		}
+	}
+	for t, v := range d.Components {
+		es.Components[t] = v
+	}
+	return es
+}
+
+// findSnapshot returns the snapshot in history with the given tick, or nil.
+func findSnapshot(history []*Snapshot, tick uint64) *Snapshot {
+	for _, s := range history {
+		if s.Tick == tick {
+			return s
+		}
+	}
+	return nil
+}
+
+// pushSnapshot appends s to history, keeping the last deltaHistory entries.
+func pushSnapshot(history []*Snapshot, s *Snapshot) []*Snapshot {
+	if len(history) == deltaHistory {
+		copy(history, history[1:])
+		history = history[:deltaHistory-1]
+	}
+	return append(history, s)
+}
+
+// =============================================================================
+// DeltaServerTransport
+// =============================================================================
+
+// DeltaServerTransport wraps a [ServerTransport] to send each client a delta
+// snapshot relative to the last snapshot that client acknowledged, instead of
+// the full entity list every time.
+//
+// The server hands it full snapshots as usual, broadcast or per client. It
+// keeps the last 32 sent to each client as baselines and tracks each client's
+// acknowledgements, which it removes from ReceiveCommands. A client gets a
+// full snapshot when it has acknowledged nothing yet or when its last
+// acknowledged snapshot is no longer kept, so clients that never acknowledge
+// (plain, non-delta clients) keep working.
+//
+// The wrapped transport must implement [ClientSnapshotSender] and report
+// connections with [CommandClientConnected]; otherwise every snapshot is
+// broadcast in full, SendSnapshotTo sends nothing and CanSendTo reports false. Pair it with
+// [DeltaClientTransport] on each client. All methods except Close must be
+// called from the simulation goroutine.
+//
+//	srvT, _ := transport.NewTCPServerTransport(":7777")
+//	server := simulation.NewServer(cfg, world, nil, transport.NewDeltaServerTransport(srvT), codec)
+type DeltaServerTransport struct {
+	inner   ServerTransport
+	sender  ClientSnapshotSender
+	clients map[ClientID]*deltaClient
+}
+
+// deltaClient is the baseline state of one connected client.
+type deltaClient struct {
+	acked   uint64      // last acknowledged tick, 0 if none
+	history []*Snapshot // last full snapshots sent to the client
+}
+
+// NewDeltaServerTransport wraps t with delta compression.
+func NewDeltaServerTransport(t ServerTransport) *DeltaServerTransport {
+	sender, _ := t.(ClientSnapshotSender)
+	return &DeltaServerTransport{
+		inner:   t,
+		sender:  sender,
+		clients: make(map[ClientID]*deltaClient),
+	}
+}
+
+// ReceiveCommands drains the wrapped transport, recording connections and
+// acknowledgements. Acknowledgements are not returned.
+func (t *DeltaServerTransport) ReceiveCommands() []*Command {
+	cmds := t.inner.ReceiveCommands()
+	n := 0
+	for _, cmd := range cmds {
+		switch cmd.Type {
+		case CommandClientConnected:
+			t.clients[cmd.ClientID] = &deltaClient{}
+		case CommandClientDisconnected:
+			delete(t.clients, cmd.ClientID)
+		case CommandSnapshotAck:
+			if c, ok := t.clients[cmd.ClientID]; ok && cmd.Tick > c.acked {
+				c.acked = cmd.Tick
+			}
+			continue
+		}
+		cmds[n] = cmd
+		n++
+	}
+	clear(cmds[n:])
+	return cmds[:n]
+}
+
+// SendSnapshot sends snapshot, which must be a full snapshot, to every
+// client as a delta against its last acknowledged snapshot, or in full.
+// snapshot is kept as a baseline and must not be modified afterwards.
+func (t *DeltaServerTransport) SendSnapshot(snapshot *Snapshot) {
+	if !t.CanSendTo() {
+		t.inner.SendSnapshot(snapshot)
+		return
+	}
+	// Clients that acknowledged the same broadcast share its delta.
+	deltas := make(map[*Snapshot]*Snapshot)
+	for id, c := range t.clients {
+		t.send(id, c, snapshot, deltas)
+	}
+}
+
+// CanSendTo reports whether the wrapped transport can address single clients.
+func (t *DeltaServerTransport) CanSendTo() bool {
+	return t.sender != nil && t.sender.CanSendTo()
+}
+
+// SendSnapshotTo sends snapshot, which must be a full snapshot, to the client
+// with the given ID as a delta against its last acknowledged snapshot, or in
+// full. snapshot is kept as a baseline and must not be modified afterwards.
+func (t *DeltaServerTransport) SendSnapshotTo(id ClientID, snapshot *Snapshot) {
+	if c, ok := t.clients[id]; ok && t.CanSendTo() {
+		t.send(id, c, snapshot, nil)
+	}
+}
+
+func (t *DeltaServerTransport) send(id ClientID, c *deltaClient, snapshot *Snapshot, deltas map[*Snapshot]*Snapshot) {
+	out := snapshot
+	if baseline := findSnapshot(c.history, c.acked); c.acked != 0 && baseline != nil {
+		var ok bool
+		if out, ok = deltas[baseline]; !ok {
+			out = DiffSnapshots(baseline, snapshot)
+			if deltas != nil {
+				deltas[baseline] = out
+			}
+		}
+	}
+	c.history = pushSnapshot(c.history, snapshot)
+	t.sender.SendSnapshotTo(id, out)
+}
+
+// Close closes the wrapped transport. Safe to call multiple times.
+func (t *DeltaServerTransport) Close() {
+	t.inner.Close()
+}
+
+// =============================================================================
+// DeltaClientTransport
+// =============================================================================
+
+// DeltaClientTransport wraps a [ClientTransport] to reconstruct the delta
+// snapshots sent by a [DeltaServerTransport]. ReceiveSnapshot always returns
+// a full snapshot, so the SnapshotCodec is unaware of deltas, and
+// acknowledges it to the server. Full snapshots pass through unchanged.
+//
+// Returned snapshots are kept as baselines for later deltas and must be
+// treated as read-only by SnapshotCodec.Decode.
+//
+//	cliT, _ := transport.NewTCPClientTransport(addr)
+//	c := client.NewClient(transport.NewDeltaClientTransport(cliT), codec, state, world, nil, cfg)
+type DeltaClientTransport struct {
+	inner   ClientTransport
+	history []*Snapshot
+}
+
+// NewDeltaClientTransport wraps t to reconstruct delta snapshots.
+func NewDeltaClientTransport(t ClientTransport) *DeltaClientTransport {
+	return &DeltaClientTransport{inner: t}
+}
+
+// SendCommand forwards cmd to the wrapped transport.
+func (t *DeltaClientTransport) SendCommand(cmd *Command) {
+	t.inner.SendCommand(cmd)
+}
+
+// ReceiveSnapshot returns the most recent snapshot as a full snapshot, or nil
+// if none has arrived. A delta whose baseline is unknown is logged and
+// dropped; the server falls back to a full snapshot once it stops keeping
+// the baseline this client last acknowledged.
+func (t *DeltaClientTransport) ReceiveSnapshot() *Snapshot {
+	snap := t.inner.ReceiveSnapshot()
+	if snap == nil {
+		return nil
+	}
+	if snap.IsDelta() {
+		baseline := findSnapshot(t.history, snap.Baseline)
+		if baseline == nil {
+			log.Printf("transport/delta client: dropping tick %d: baseline %d unknown", snap.Tick, snap.Baseline)
+			return nil
+		}
+		snap = ApplyDelta(baseline, snap)
+	}
+	t.history = pushSnapshot(t.history, snap)
+	t.inner.SendCommand(&Command{Type: CommandSnapshotAck, Tick: snap.Tick})
+	return snap
+}
+
+// ClientID returns the wrapped transport's ClientID, or 0 if it does not
+// implement [IdentifiedClient].
+func (t *DeltaClientTransport) ClientID() ClientID {
+	if ic, ok := t.inner.(IdentifiedClient); ok {
+		return ic.ClientID()
+	}
+	return 0
+}
+
+// Close closes the wrapped transport. Safe to call multiple times.
+func (t *DeltaClientTransport) Close() {
+	t.inner.Close()
+}
@@ -0,0 +1,128 @@
+package transport
+
+import (
+	"testing"
+
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deltaPos struct{ X, Y int }
+
+func deltaEntity(id string, comps map[ecs.ComponentType]ComponentData) *EntitySnapshot {
+	return &EntitySnapshot{ID: id, Blueprint: "unit", Components: comps}
+}
+
+func deltaWorld(tick uint64, entities ...*EntitySnapshot) *Snapshot {
+	return &Snapshot{Tick: tick, Timestamp: int64(tick), Entities: entities}
+}
+
+func TestDiffAndApplyDelta(t *testing.T) {
+	assert := assert.New(t)
+	baseline := deltaWorld(1,
+		deltaEntity("a", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{1, 1}, "Name": "alpha"}),
+		deltaEntity("b", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{2, 2}}),
+		deltaEntity("c", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{3, 3}, "Burning": true}),
+	)
+	current := deltaWorld(2,
+		deltaEntity("a", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{1, 2}, "Name": "alpha"}),
+		deltaEntity("c", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{3, 3}}),
+		deltaEntity("d", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{4, 4}}),
+	)
+
+	current.ClientTicks = map[ClientID]uint64{1: 12}
+	delta := DiffSnapshots(baseline, current)
+	assert.True(delta.IsDelta())
+	assert.Equal(uint64(1), delta.Baseline)
+	assert.Equal([]string{"b"}, delta.Removed)
+	require.Len(t, delta.Entities, 3)
+	assert.Equal(map[ecs.ComponentType]ComponentData{"Pos": deltaPos{1, 2}}, delta.Entities[0].Components, "Expected only changed components")
+	assert.Nil(delta.Entities[1].Components)
+	assert.Equal([]ecs.ComponentType{"Burning"}, delta.Entities[1].Removed)
+	assert.Same(current.Entities[2], delta.Entities[2])
+
+	full := ApplyDelta(baseline, delta)
+	assert.False(full.IsDelta())
+	assert.Equal(current, full)
+
+	assert.Empty(DiffSnapshots(current, current).Entities)
+}
+
+func TestDeltaServerTransportTracksAcks(t *testing.T) {
+	assert := assert.New(t)
+	local := NewLocalServerTransport()
+	srv := NewDeltaServerTransport(local)
+	cli := local.Connect()
+	assert.Len(srv.ReceiveCommands(), 1)
+
+	s1 := deltaWorld(1, deltaEntity("a", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{0, 0}}))
+	srv.SendSnapshot(s1)
+	assert.Same(s1, cli.ReceiveSnapshot(), "Expected a full snapshot before any acknowledgement")
+
+	cli.SendCommand(&Command{Type: CommandSnapshotAck, Tick: 1})
+	cli.SendCommand(&Command{Type: "move"})
+	cmds := srv.ReceiveCommands()
+	require.Len(t, cmds, 1, "Expected acknowledgements to be consumed")
+	assert.Equal(CommandType("move"), cmds[0].Type)
+
+	s2 := deltaWorld(2, deltaEntity("a", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{1, 0}}))
+	srv.SendSnapshot(s2)
+	got := cli.ReceiveSnapshot()
+	assert.Equal(uint64(1), got.Baseline)
+	assert.Equal(s2, ApplyDelta(s1, got))
+
+	for tick := uint64(3); tick < 3+deltaHistory; tick++ {
+		srv.SendSnapshot(deltaWorld(tick))
+	}
+	assert.False(cli.ReceiveSnapshot().IsDelta(), "Expected a full snapshot once the baseline is too old")
+}
+
+func TestDeltaClientTransportReconstructsSnapshots(t *testing.T) {
+	assert := assert.New(t)
+	local := NewLocalServerTransport()
+	srv := NewDeltaServerTransport(local)
+	cli := NewDeltaClientTransport(local.Connect())
+	srv.ReceiveCommands()
+
+	var sent []*Snapshot
+	for tick := uint64(1); tick <= 4; tick++ {
+		s := deltaWorld(tick,
+			deltaEntity("a", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{int(tick), 0}}),
+			deltaEntity("b", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{7, 7}}),
+		)
+		sent = append(sent, s)
+		srv.SendSnapshot(s)
+		assert.Equal(s, cli.ReceiveSnapshot())
+		assert.Empty(srv.ReceiveCommands())
+	}
+	assert.Len(srv.clients[1].history, 4)
+
+	assert.Nil(cli.ReceiveSnapshot())
+	local.SendSnapshotTo(1, DiffSnapshots(deltaWorld(99), sent[3]))
+	assert.Nil(cli.ReceiveSnapshot(), "Expected deltas against unknown baselines to be dropped")
+}
+
+func TestDeltaServerTransportKeepsBaselinesPerClient(t *testing.T) {
+	local := NewLocalServerTransport()
+	srv := NewDeltaServerTransport(local)
+	red, blue := local.Connect(), local.Connect()
+	srv.ReceiveCommands()
+
+	redView := deltaWorld(1, deltaEntity("r", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{1, 1}}))
+	blueView := deltaWorld(1, deltaEntity("b", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{2, 2}}))
+	srv.SendSnapshotTo(1, redView)
+	srv.SendSnapshotTo(2, blueView)
+	assert.Same(t, redView, red.ReceiveSnapshot())
+	assert.Same(t, blueView, blue.ReceiveSnapshot())
+	red.SendCommand(&Command{Type: CommandSnapshotAck, Tick: 1})
+	blue.SendCommand(&Command{Type: CommandSnapshotAck, Tick: 1})
+	srv.ReceiveCommands()
+
+	next := deltaWorld(2, deltaEntity("b", map[ecs.ComponentType]ComponentData{"Pos": deltaPos{2, 3}}))
+	srv.SendSnapshotTo(2, next)
+	got := blue.ReceiveSnapshot()
+	assert.Equal(t, uint64(1), got.Baseline)
+	assert.Equal(t, next, ApplyDelta(blueView, got), "Expected the delta against the client's own baseline")
+	assert.Nil(t, red.ReceiveSnapshot())
+}
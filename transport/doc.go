@@ -6,16 +6,23 @@
 //   - [ClientTransport]: client side - sends commands to the server, reads snapshots.
 //   - [LocalTransport]: in-process implementation backed by Go channels for
 //     single-player or same-executable multiplayer. Zero serialization overhead.
+//   - [ClientID]: identifies the client a [Command] came from. Server transports
+//     stamp it on every command and report connections with
+//     [CommandClientConnected] and [CommandClientDisconnected].
 //   - [TCPServerTransport] / [TCPClientTransport]: network implementation using
-//     length-prefixed JSON over TCP. TCP_NODELAY is set for lower latency.
+//     length-prefixed messages over TCP, encoded by a [WireCodec] ([JSONCodec]
+//     or [BinaryCodec]). TCP_NODELAY is set for lower latency.
+//   - [DeltaServerTransport] / [DeltaClientTransport]: wrappers that send each
+//     client only what changed since the last snapshot it acknowledged.
 //
 // The design mirrors Quake's netcode abstraction: the same game code runs whether
 // the transport is local channels or TCP. Swap the implementation at startup;
 // server and client code are unaware of the difference.
 //
-// Serialization note for TCP: [Command.Payload] and [EntitySnapshot.Components]
-// values are encoded with encoding/json. Concrete struct types round-trip
-// correctly. Values stored as bare interface{} will decode as
-// map[string]interface{} on the remote side; the game must handle that
-// (e.g. re-unmarshal into a concrete type, or store json.RawMessage directly).
+// Serialization note for TCP: with the default [JSONCodec],
+// [Command.Payload] and [EntitySnapshot.Components] values are encoded with
+// encoding/json. Concrete struct types round-trip correctly. Values stored as
+// bare interface{} will decode as map[string]interface{} on the remote side;
+// the game must handle that (e.g. re-unmarshal into a concrete type, or store
+// json.RawMessage directly), or use a [BinaryCodec] with the types registered.
 package transport
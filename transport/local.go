@@ -8,87 +8,182 @@ const (
 )
 
 // LocalTransport is an in-process, channel-backed implementation of
-// [ServerTransport] and [ClientTransport].
+// [ServerTransport] with any number of [ClientTransport] halves.
 //
-// Use it for single-player or split-screen games where the server and client
+// Use it for single-player or split-screen games where the server and clients
 // share a single executable. There is no serialization cost - commands and
 // snapshots are passed as pointer values through buffered Go channels.
 //
-// Create an instance with [NewLocalTransport].
+// Each client connected with [LocalTransport.Connect] gets its own
+// [ClientID], which is stamped on every command it sends, and receives every
+// snapshot. Connecting and closing a client queue [CommandClientConnected]
+// and [CommandClientDisconnected] for the server, as TCP connections do.
+//
+// Create an instance with [NewLocalTransport] or [NewLocalServerTransport].
 type LocalTransport struct {
-	commands  chan *Command
-	snapshots chan *Snapshot
-	closeOnce sync.Once
+	commands commandQueue
+
+	mu      sync.Mutex
+	clients []*localClientSide
+	nextID  ClientID
+	closed  bool
 }
 
 // NewLocalTransport returns a joined ServerTransport/ClientTransport pair that
-// communicate through shared in-process channels.
+// communicate through shared in-process channels. The client is ClientID 1.
 //
 //	srvT, cliT := transport.NewLocalTransport()
 //	go server.Run(srvT)
 //	ebiten.RunGame(client.New(cliT))
 func NewLocalTransport() (ServerTransport, ClientTransport) {
-	lt := &LocalTransport{
-		commands:  make(chan *Command, defaultCommandBufSize),
+	lt := NewLocalServerTransport()
+	return lt, lt.Connect()
+}
+
+// NewLocalServerTransport returns a LocalTransport with no clients. Call
+// Connect once per local player.
+//
+//	srvT := transport.NewLocalServerTransport()
+//	player1, player2 := srvT.Connect(), srvT.Connect()
+func NewLocalServerTransport() *LocalTransport {
+	return &LocalTransport{commands: commandQueue{limit: defaultCommandBufSize}}
+}
+
+// Connect returns the ClientTransport of a new local client. Its
+// [CommandClientConnected] is queued before any of its commands. Connecting
+// after Close returns a client whose commands are dropped and that never
+// receives a snapshot.
+func (t *LocalTransport) Connect() ClientTransport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	c := &localClientSide{
+		t:         t,
+		id:        t.nextID,
 		snapshots: make(chan *Snapshot, defaultSnapshotBufSize),
 	}
-	return &localServerSide{lt}, &localClientSide{lt}
+	if t.closed {
+		c.closed = true
+		close(c.snapshots)
+		return c
+	}
+	t.clients = append(t.clients, c)
+	t.commands.push(&Command{Type: CommandClientConnected, ClientID: c.id})
+	return c
 }
 
 // --- server side ---
 
-type localServerSide struct{ t *LocalTransport }
+// ReceiveCommands drains all pending commands from all local clients.
+// Returns an empty (non-nil) slice when no commands are queued. Non-blocking.
+func (t *LocalTransport) ReceiveCommands() []*Command {
+	return t.commands.drain()
+}
 
-func (s *localServerSide) ReceiveCommands() []*Command {
-	var cmds []*Command
-	for {
-		select {
-		case cmd := <-s.t.commands:
-			cmds = append(cmds, cmd)
-		default:
-			if cmds == nil {
-				cmds = []*Command{} // always return non-nil
-			}
-			return cmds
+// SendSnapshot delivers snapshot to every connected local client. Clients
+// share the snapshot pointer and must treat it as read-only.
+func (t *LocalTransport) SendSnapshot(snapshot *Snapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, c := range t.clients {
+		c.sendSnapshot(snapshot)
+	}
+}
+
+// CanSendTo reports true: local clients can always be addressed.
+func (t *LocalTransport) CanSendTo() bool {
+	return true
+}
+
+// SendSnapshotTo delivers snapshot to the local client with the given ID.
+func (t *LocalTransport) SendSnapshotTo(id ClientID, snapshot *Snapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, c := range t.clients {
+		if c.id == id {
+			c.sendSnapshot(snapshot)
+			return
+		}
+	}
+}
+
+// Close disconnects every client. Safe to call multiple times.
+func (t *LocalTransport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for _, c := range t.clients {
+		c.closed = true
+		close(c.snapshots)
+	}
+	t.clients = nil
+}
+
+// disconnect removes c, queuing its CommandClientDisconnected.
+func (t *LocalTransport) disconnect(c *localClientSide) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.snapshots)
+	for i, other := range t.clients {
+		if other == c {
+			t.clients = append(t.clients[:i], t.clients[i+1:]...)
+			break
 		}
 	}
+	t.commands.push(&Command{Type: CommandClientDisconnected, ClientID: c.id})
 }
 
-func (s *localServerSide) SendSnapshot(snapshot *Snapshot) {
+// --- client side ---
+
+// localClientSide is one client of a LocalTransport. closed and the
+// snapshots channel are guarded by the transport's mu.
+type localClientSide struct {
+	t         *LocalTransport
+	id        ClientID
+	snapshots chan *Snapshot
+	closed    bool
+}
+
+// sendSnapshot is called with t.mu held.
+func (c *localClientSide) sendSnapshot(snapshot *Snapshot) {
 	select {
-	case s.t.snapshots <- snapshot:
+	case c.snapshots <- snapshot:
 	default:
 		// Buffer is full - drop oldest snapshot and try again.
 		// This ensures the client always gets the most recent state, not a stale one.
 		select {
-		case <-s.t.snapshots:
+		case <-c.snapshots:
 		default:
 		}
 		select {
-		case s.t.snapshots <- snapshot:
+		case c.snapshots <- snapshot:
 		default:
 		}
 	}
 }
 
-func (s *localServerSide) Close() {
-	s.t.closeOnce.Do(func() {
-		close(s.t.commands)
-		close(s.t.snapshots)
-	})
-}
-
-// --- client side ---
-
-type localClientSide struct{ t *LocalTransport }
-
+// SendCommand stamps cmd with this client's ID and queues it for the server.
+// Commands sent after either side is closed, and connection notifications
+// only the server may queue, are dropped.
 func (c *localClientSide) SendCommand(cmd *Command) {
-	select {
-	case c.t.commands <- cmd:
-	default:
-		// Server buffer full - drop command. Shouldn't happen at 60 TPS with
-		// a 64-slot buffer unless the server is severely stalled.
+	// Held while pushing so the command cannot land after this client's
+	// CommandClientDisconnected.
+	c.t.mu.Lock()
+	defer c.t.mu.Unlock()
+	if c.closed || isConnectionCommand(cmd.Type) {
+		return
 	}
+	cmd.ClientID = c.id
+	// Drops the command if the server buffer is full. Shouldn't happen at
+	// 60 TPS with a 64-slot buffer unless the server is severely stalled.
+	c.t.commands.push(cmd)
 }
 
 func (c *localClientSide) ReceiveSnapshot() *Snapshot {
@@ -96,7 +191,7 @@ func (c *localClientSide) ReceiveSnapshot() *Snapshot {
 	var latest *Snapshot
 	for {
 		select {
-		case snap, ok := <-c.t.snapshots:
+		case snap, ok := <-c.snapshots:
 			if !ok {
 				return latest // channel closed
 			}
@@ -107,11 +202,13 @@ func (c *localClientSide) ReceiveSnapshot() *Snapshot {
 	}
 }
 
+// ClientID returns the ID this client was assigned by Connect.
+func (c *localClientSide) ClientID() ClientID {
+	return c.id
+}
+
+// Close disconnects this client only; the server and other clients keep
+// running. Safe to call multiple times.
 func (c *localClientSide) Close() {
-	// Client close delegates to server side since they share the transport.
-	// Calling Close from either side is safe.
-	c.t.closeOnce.Do(func() {
-		close(c.t.commands)
-		close(c.t.snapshots)
-	})
+	c.t.disconnect(c)
 }
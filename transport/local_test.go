@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalTransportAttributesCommands(t *testing.T) {
+	assert := assert.New(t)
+	srv := NewLocalServerTransport()
+	p1, p2 := srv.Connect(), srv.Connect()
+	assert.Equal(ClientID(2), p2.(IdentifiedClient).ClientID())
+
+	p1.SendCommand(&Command{Type: "move", ClientID: 7})
+	p2.SendCommand(&Command{Type: "jump"})
+	p2.SendCommand(&Command{Type: CommandClientDisconnected})
+	p2.Close()
+	p2.SendCommand(&Command{Type: "late"})
+
+	var got []Command
+	for _, cmd := range srv.ReceiveCommands() {
+		got = append(got, *cmd)
+	}
+	assert.Equal([]Command{
+		{Type: CommandClientConnected, ClientID: 1},
+		{Type: CommandClientConnected, ClientID: 2},
+		{Type: "move", ClientID: 1},
+		{Type: "jump", ClientID: 2},
+		{Type: CommandClientDisconnected, ClientID: 2},
+	}, got)
+	assert.NotNil(srv.ReceiveCommands())
+	assert.Empty(srv.ReceiveCommands())
+
+	snap := &Snapshot{Tick: 3}
+	srv.SendSnapshot(snap)
+	assert.Same(snap, p1.ReceiveSnapshot())
+	assert.Nil(p2.ReceiveSnapshot(), "Expected closed clients to stop receiving snapshots")
+
+	srv.Close()
+	srv.Close()
+	p1.Close()
+	assert.Empty(srv.ReceiveCommands(), "Expected no disconnect after the server closed")
+}
+
+func TestLocalTransportNeverDropsConnections(t *testing.T) {
+	srv, cli := NewLocalTransport()
+	for i := 0; i < defaultCommandBufSize*2; i++ {
+		cli.SendCommand(&Command{Type: "spam"})
+	}
+	cli.Close()
+
+	cmds := srv.ReceiveCommands()
+	assert.Len(t, cmds, defaultCommandBufSize+1)
+	assert.Equal(t, CommandClientDisconnected, cmds[len(cmds)-1].Type)
+}
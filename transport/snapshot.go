@@ -14,10 +14,10 @@ type ComponentData = any
 
 // EntitySnapshot captures the state of one entity at a given server tick.
 type EntitySnapshot struct {
-	// ID is a game-assigned identifier for the entity.
-	// mlge entities have no built-in ID; games must assign one via a component
-	// (e.g., an IDComponent) or another scheme. The SnapshotCodec is responsible
-	// for populating this field.
+	// ID identifies the entity across snapshots. Entities owned by an
+	// ecs.World should use e.ID.String() (parse back with ecs.ParseEntityID);
+	// games not using ecs.World may assign their own scheme. The SnapshotCodec
+	// is responsible for populating this field.
 	ID string
 
 	// Blueprint is the entity's blueprint name, copied from ecs.Entity.Blueprint.
@@ -25,7 +25,12 @@ type EntitySnapshot struct {
 
 	// Components maps component type → component value.
 	// The SnapshotCodec decides which components to include and how to encode them.
+	// In a delta snapshot it holds only the components that changed.
 	Components map[ecs.ComponentType]ComponentData
+
+	// Removed lists the components dropped since the baseline. Only set in
+	// delta snapshots.
+	Removed []ecs.ComponentType `json:",omitempty"`
 }
 
 // Snapshot is the authoritative world state produced by the server each tick.
@@ -37,9 +42,31 @@ type Snapshot struct {
 	// Timestamp is when the snapshot was produced (UnixNano).
 	Timestamp int64
 
-	// Entities holds one snapshot per simulated entity.
-	// Phase 1 always sends the full entity list (no delta compression).
+	// Entities holds one snapshot per simulated entity. In a delta snapshot
+	// it holds only the entities created or changed since the baseline.
 	Entities []*EntitySnapshot
+
+	// Baseline is the tick of the snapshot a delta snapshot is relative to,
+	// or zero for a full snapshot. See [DiffSnapshots].
+	Baseline uint64 `json:",omitempty"`
+
+	// Removed lists the IDs of entities removed since the baseline. Only set
+	// in delta snapshots.
+	Removed []string `json:",omitempty"`
+
+	// ClientTicks maps each client that sent predicted commands to the
+	// prediction tick this snapshot corresponds to: the Tick of the last
+	// command the server processed from it, plus the server ticks simulated
+	// since. A client.Predictor replays its commands after that tick.
+	// simulation.Server sends each client only its own entry unless the
+	// transport can only broadcast.
+	ClientTicks map[ClientID]uint64 `json:",omitempty"`
+}
+
+// IsDelta reports whether s is a delta snapshot that must be applied to its
+// baseline with [ApplyDelta] before decoding.
+func (s *Snapshot) IsDelta() bool {
+	return s.Baseline != 0
 }
 
 // NewSnapshot is a convenience constructor used by the Server.
@@ -2,40 +2,24 @@ package transport
 
 import (
 	"encoding/binary"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
-// tcpEnvelope is the wire format wrapping all TCP messages.
-// Kind routes the payload to the correct decode target on the receiver.
-type tcpEnvelope struct {
-	Kind    string          `json:"k"`
-	Payload json.RawMessage `json:"p"`
-}
-
-const (
-	tcpKindCommand  = "cmd"
-	tcpKindSnapshot = "snap"
-)
-
-// writeMsg writes a length-prefixed JSON message to conn.
-// Wire format: 4-byte big-endian uint32 length, then JSON bytes.
-func writeMsg(conn net.Conn, env *tcpEnvelope) error {
-	data, err := json.Marshal(env)
-	if err != nil {
-		return err
-	}
+// writeMsg writes a length-prefixed message to conn.
+// Wire format: 4-byte big-endian uint32 length, then the WireCodec bytes.
+func writeMsg(conn net.Conn, data []byte) error {
 	var header [4]byte
 	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
 	if _, err := conn.Write(header[:]); err != nil {
 		return err
 	}
-	_, err = conn.Write(data)
+	_, err := conn.Write(data)
 	return err
 }
 
@@ -43,8 +27,8 @@ func writeMsg(conn net.Conn, env *tcpEnvelope) error {
 // Protects against a misbehaving peer sending a huge length header.
 const maxMsgSize = 4 << 20 // 4 MiB
 
-// readMsg reads a single length-prefixed JSON message from conn.
-func readMsg(conn net.Conn) (*tcpEnvelope, error) {
+// readMsg reads a single length-prefixed message from conn.
+func readMsg(conn net.Conn) ([]byte, error) {
 	var header [4]byte
 	if _, err := io.ReadFull(conn, header[:]); err != nil {
 		return nil, err
@@ -57,13 +41,13 @@ func readMsg(conn net.Conn) (*tcpEnvelope, error) {
 	if _, err := io.ReadFull(conn, buf); err != nil {
 		return nil, err
 	}
-	var env tcpEnvelope
-	if err := json.Unmarshal(buf, &env); err != nil {
-		return nil, err
-	}
-	return &env, nil
+	return buf, nil
 }
 
+// welcomeTimeout bounds how long the server waits to send a new client its
+// ClientID before dropping the connection.
+const welcomeTimeout = 5 * time.Second
+
 // setNoDelay disables Nagle buffering on a TCP connection to reduce latency.
 func setNoDelay(conn net.Conn) {
 	if tc, ok := conn.(*net.TCPConn); ok {
@@ -80,44 +64,68 @@ func setNoDelay(conn net.Conn) {
 // It listens for incoming client connections, reads [Command] messages from
 // each, and broadcasts [Snapshot] messages to all connected clients.
 //
-// Serialization note: [Command.Payload] and [EntitySnapshot.Components] values
-// are encoded as JSON. Concrete types round-trip correctly. Values stored as
-// bare interface{} will decode as map[string]interface{} on the remote side;
-// the game must handle that (e.g. use json.RawMessage or re-unmarshal).
+// Serialization note: messages are encoded with a [WireCodec], [JSONCodec]
+// by default. With JSON, [Command.Payload] and [EntitySnapshot.Components]
+// values stored as bare interface{} will decode as map[string]interface{} on
+// the remote side; the game must handle that (e.g. use json.RawMessage or
+// re-unmarshal), or use a [BinaryCodec], which restores registered types.
+//
+// Each accepted connection is assigned the next [ClientID], which is stamped
+// on every command read from it. The client is sent its ID first and only
+// then receives snapshots; a client that does not take the ID within five
+// seconds is dropped. Joining and losing a connection queue
+// [CommandClientConnected] and [CommandClientDisconnected] for the server.
 //
 // TCP_NODELAY is set on every accepted connection.
 //
-// Use [NewTCPServerTransport] to create an instance.
+// Use [NewTCPServerTransport] or [NewTCPServerTransportWithCodec] to create
+// an instance.
 type TCPServerTransport struct {
 	listener net.Listener
-	commands chan *Command
+	codec    WireCodec
+	commands commandQueue
 
-	mu     sync.Mutex
-	conns  []*tcpPeer
-	closed atomic.Bool
-	wg     sync.WaitGroup
+	mu      sync.Mutex
+	conns   []*tcpPeer
+	joining map[*tcpPeer]bool // accepted peers still being welcomed
+	nextID  ClientID
+	closed  atomic.Bool
+	wg      sync.WaitGroup
 }
 
 // tcpPeer wraps a single client connection on the server side.
 type tcpPeer struct {
+	id     ClientID
 	conn   net.Conn
 	sendMu sync.Mutex
 }
 
 // NewTCPServerTransport starts a TCP listener on addr (e.g. ":7777") and
-// returns a ready-to-use [ServerTransport]. Call Close when done.
+// returns a ready-to-use [ServerTransport] speaking JSON. Call Close when done.
 func NewTCPServerTransport(addr string) (ServerTransport, error) {
+	return NewTCPServerTransportWithCodec(addr, JSONCodec{})
+}
+
+// NewTCPServerTransportWithCodec is like [NewTCPServerTransport] but encodes
+// messages with codec. Clients must use the same codec.
+func NewTCPServerTransportWithCodec(addr string, codec WireCodec) (ServerTransport, error) {
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
+	return serveTCP(ln, codec), nil
+}
+
+// serveTCP starts accepting clients on ln.
+func serveTCP(ln net.Listener, codec WireCodec) *TCPServerTransport {
 	t := &TCPServerTransport{
 		listener: ln,
-		commands: make(chan *Command, defaultCommandBufSize),
+		codec:    codec,
+		commands: commandQueue{limit: defaultCommandBufSize},
 	}
 	t.wg.Add(1)
 	go t.acceptLoop()
-	return t, nil
+	return t
 }
 
 func (t *TCPServerTransport) acceptLoop() {
@@ -132,15 +140,55 @@ func (t *TCPServerTransport) acceptLoop() {
 			return
 		}
 		setNoDelay(conn)
-		peer := &tcpPeer{conn: conn}
 		t.mu.Lock()
-		t.conns = append(t.conns, peer)
+		t.nextID++
+		peer := &tcpPeer{id: t.nextID, conn: conn}
+		if t.joining == nil {
+			t.joining = make(map[*tcpPeer]bool)
+		}
+		t.joining[peer] = true
 		t.mu.Unlock()
 		t.wg.Add(1)
-		go t.peerReadLoop(peer)
+		go t.servePeer(peer)
 	}
 }
 
+// servePeer welcomes a new peer, registers it for snapshots and reads its
+// commands until it disconnects.
+func (t *TCPServerTransport) servePeer(peer *tcpPeer) {
+	welcomed := t.welcome(peer)
+	t.mu.Lock()
+	delete(t.joining, peer)
+	if !welcomed || t.closed.Load() {
+		t.mu.Unlock()
+		peer.conn.Close()
+		t.wg.Done()
+		return
+	}
+	t.conns = append(t.conns, peer)
+	t.mu.Unlock()
+	t.commands.push(&Command{Type: CommandClientConnected, ClientID: peer.id})
+	t.peerReadLoop(peer)
+}
+
+// welcome tells a new peer its ClientID by sending it a
+// CommandClientConnected, which TCPClientTransport records. The peer is not
+// yet registered, so nothing else writes to it.
+func (t *TCPServerTransport) welcome(peer *tcpPeer) bool {
+	data, err := t.codec.EncodeCommand(&Command{Type: CommandClientConnected, ClientID: peer.id})
+	if err != nil {
+		log.Printf("transport/tcp server: encode welcome: %v", err)
+		return false
+	}
+	_ = peer.conn.SetWriteDeadline(time.Now().Add(welcomeTimeout))
+	err = writeMsg(peer.conn, data)
+	_ = peer.conn.SetWriteDeadline(time.Time{})
+	if err != nil && !t.closed.Load() {
+		log.Printf("transport/tcp server: send welcome: %v", err)
+	}
+	return err == nil
+}
+
 func (t *TCPServerTransport) peerReadLoop(peer *tcpPeer) {
 	defer func() {
 		peer.conn.Close()
@@ -155,59 +203,46 @@ func (t *TCPServerTransport) peerReadLoop(peer *tcpPeer) {
 			}
 		}
 		t.mu.Unlock()
+		t.commands.push(&Command{Type: CommandClientDisconnected, ClientID: peer.id})
 		t.wg.Done()
 	}()
 	for {
-		env, err := readMsg(peer.conn)
+		data, err := readMsg(peer.conn)
 		if err != nil {
 			if !t.closed.Load() {
 				log.Printf("transport/tcp server: peer read error: %v", err)
 			}
 			return
 		}
-		if env.Kind != tcpKindCommand {
-			continue
-		}
-		var cmd Command
-		if err := json.Unmarshal(env.Payload, &cmd); err != nil {
+		cmd, _, err := t.codec.Decode(data)
+		if err != nil {
 			log.Printf("transport/tcp server: decode command: %v", err)
 			continue
 		}
-		select {
-		case t.commands <- &cmd:
-		default:
-			// Server command buffer full - drop. Should not happen in normal play.
+		if cmd == nil || isConnectionCommand(cmd.Type) {
+			continue // only the server reports connections
 		}
+		cmd.ClientID = peer.id
+		// Dropped if the server command buffer is full. Should not happen in normal play.
+		t.commands.push(cmd)
 	}
 }
 
 // ReceiveCommands drains all pending commands from all connected clients.
 // Returns an empty (non-nil) slice when no commands are queued. Non-blocking.
 func (t *TCPServerTransport) ReceiveCommands() []*Command {
-	var cmds []*Command
-	for {
-		select {
-		case cmd := <-t.commands:
-			cmds = append(cmds, cmd)
-		default:
-			if cmds == nil {
-				cmds = []*Command{}
-			}
-			return cmds
-		}
-	}
+	return t.commands.drain()
 }
 
-// SendSnapshot JSON-encodes snapshot and writes it to every connected client.
+// SendSnapshot encodes snapshot once and writes it to every connected client.
 // Clients that cannot be written to are logged; the error does not stop
 // delivery to other clients.
 func (t *TCPServerTransport) SendSnapshot(snapshot *Snapshot) {
-	payload, err := json.Marshal(snapshot)
+	data, err := t.codec.EncodeSnapshot(snapshot)
 	if err != nil {
 		log.Printf("transport/tcp server: encode snapshot: %v", err)
 		return
 	}
-	env := &tcpEnvelope{Kind: tcpKindSnapshot, Payload: json.RawMessage(payload)}
 
 	t.mu.Lock()
 	peers := make([]*tcpPeer, len(t.conns))
@@ -215,11 +250,44 @@ func (t *TCPServerTransport) SendSnapshot(snapshot *Snapshot) {
 	t.mu.Unlock()
 
 	for _, peer := range peers {
-		peer.sendMu.Lock()
-		if err := writeMsg(peer.conn, env); err != nil && !t.closed.Load() {
-			log.Printf("transport/tcp server: send snapshot: %v", err)
+		t.writeSnapshot(peer, data)
+	}
+}
+
+// CanSendTo reports true: every peer has its own connection.
+func (t *TCPServerTransport) CanSendTo() bool {
+	return true
+}
+
+// SendSnapshotTo encodes snapshot and writes it to the client with the
+// given ID only. Does nothing if that client is not connected.
+func (t *TCPServerTransport) SendSnapshotTo(id ClientID, snapshot *Snapshot) {
+	t.mu.Lock()
+	var peer *tcpPeer
+	for _, c := range t.conns {
+		if c.id == id {
+			peer = c
+			break
 		}
-		peer.sendMu.Unlock()
+	}
+	t.mu.Unlock()
+	if peer == nil {
+		return
+	}
+
+	data, err := t.codec.EncodeSnapshot(snapshot)
+	if err != nil {
+		log.Printf("transport/tcp server: encode snapshot: %v", err)
+		return
+	}
+	t.writeSnapshot(peer, data)
+}
+
+func (t *TCPServerTransport) writeSnapshot(peer *tcpPeer, data []byte) {
+	peer.sendMu.Lock()
+	defer peer.sendMu.Unlock()
+	if err := writeMsg(peer.conn, data); err != nil && !t.closed.Load() {
+		log.Printf("transport/tcp server: send snapshot: %v", err)
 	}
 }
 
@@ -232,6 +300,9 @@ func (t *TCPServerTransport) Close() {
 		for _, peer := range t.conns {
 			peer.conn.Close()
 		}
+		for peer := range t.joining {
+			peer.conn.Close()
+		}
 		t.mu.Unlock()
 		t.wg.Wait()
 	}
@@ -248,11 +319,17 @@ func (t *TCPServerTransport) Close() {
 // recently received snapshot is retained; older ones are discarded so the
 // caller always sees up-to-date state.
 //
+// The server sends the client its [ClientID] on connect; ClientID returns 0
+// until it has arrived.
+//
 // TCP_NODELAY is set on the connection.
 //
-// Use [NewTCPClientTransport] to create an instance.
+// Use [NewTCPClientTransport] or [NewTCPClientTransportWithCodec] to create
+// an instance.
 type TCPClientTransport struct {
-	conn net.Conn
+	conn  net.Conn
+	codec WireCodec
+	id    atomic.Uint32
 
 	mu     sync.Mutex
 	latest *Snapshot
@@ -262,14 +339,20 @@ type TCPClientTransport struct {
 }
 
 // NewTCPClientTransport dials addr (e.g. "localhost:7777") and returns a
-// ready-to-use [ClientTransport]. Call Close when done.
+// ready-to-use [ClientTransport] speaking JSON. Call Close when done.
 func NewTCPClientTransport(addr string) (ClientTransport, error) {
+	return NewTCPClientTransportWithCodec(addr, JSONCodec{})
+}
+
+// NewTCPClientTransportWithCodec is like [NewTCPClientTransport] but encodes
+// messages with codec, which must match the server's.
+func NewTCPClientTransportWithCodec(addr string, codec WireCodec) (ClientTransport, error) {
 	conn, err := net.Dial("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
 	setNoDelay(conn)
-	t := &TCPClientTransport{conn: conn}
+	t := &TCPClientTransport{conn: conn, codec: codec}
 	t.wg.Add(1)
 	go t.readLoop()
 	return t, nil
@@ -278,37 +361,39 @@ func NewTCPClientTransport(addr string) (ClientTransport, error) {
 func (t *TCPClientTransport) readLoop() {
 	defer t.wg.Done()
 	for {
-		env, err := readMsg(t.conn)
+		data, err := readMsg(t.conn)
 		if err != nil {
 			if !t.closed.Load() {
 				log.Printf("transport/tcp client: read error: %v", err)
 			}
 			return
 		}
-		if env.Kind != tcpKindSnapshot {
+		cmd, snap, err := t.codec.Decode(data)
+		if err != nil {
+			log.Printf("transport/tcp client: decode snapshot: %v", err)
 			continue
 		}
-		var snap Snapshot
-		if err := json.Unmarshal(env.Payload, &snap); err != nil {
-			log.Printf("transport/tcp client: decode snapshot: %v", err)
+		if cmd != nil && cmd.Type == CommandClientConnected {
+			t.id.Store(uint32(cmd.ClientID))
+		}
+		if snap == nil {
 			continue
 		}
 		t.mu.Lock()
-		t.latest = &snap
+		t.latest = snap
 		t.mu.Unlock()
 	}
 }
 
-// SendCommand JSON-encodes cmd and writes it to the server. Non-blocking from
+// SendCommand encodes cmd and writes it to the server. Non-blocking from
 // the caller's perspective; write errors are logged.
 func (t *TCPClientTransport) SendCommand(cmd *Command) {
-	payload, err := json.Marshal(cmd)
+	data, err := t.codec.EncodeCommand(cmd)
 	if err != nil {
 		log.Printf("transport/tcp client: encode command: %v", err)
 		return
 	}
-	env := &tcpEnvelope{Kind: tcpKindCommand, Payload: json.RawMessage(payload)}
-	if err := writeMsg(t.conn, env); err != nil && !t.closed.Load() {
+	if err := writeMsg(t.conn, data); err != nil && !t.closed.Load() {
 		log.Printf("transport/tcp client: send command: %v", err)
 	}
 }
@@ -323,6 +408,12 @@ func (t *TCPClientTransport) ReceiveSnapshot() *Snapshot {
 	return snap
 }
 
+// ClientID returns the ID the server assigned this client, or 0 if the
+// server has not sent it yet.
+func (t *TCPClientTransport) ClientID() ClientID {
+	return ClientID(t.id.Load())
+}
+
 // Close shuts down the connection and waits for the read goroutine to exit.
 // Safe to call multiple times.
 func (t *TCPClientTransport) Close() {
@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiveUntil polls srv until n commands have arrived or a second passes.
+func receiveUntil(srv ServerTransport, n int) []*Command {
+	var cmds []*Command
+	deadline := time.Now().Add(time.Second)
+	for len(cmds) < n && time.Now().Before(deadline) {
+		cmds = append(cmds, srv.ReceiveCommands()...)
+		time.Sleep(time.Millisecond)
+	}
+	return cmds
+}
+
+func TestTCPServerTransportAttributesCommands(t *testing.T) {
+	srv, err := NewTCPServerTransport("127.0.0.1:0")
+	require.NoError(t, err)
+	defer srv.Close()
+	addr := srv.(*TCPServerTransport).listener.Addr().String()
+
+	a, err := NewTCPClientTransport(addr)
+	require.NoError(t, err)
+	joined := receiveUntil(srv, 1)
+	b, err := NewTCPClientTransport(addr)
+	require.NoError(t, err)
+	joined = append(joined, receiveUntil(srv, 1)...)
+	require.Len(t, joined, 2)
+	assert.Equal(t, Command{Type: CommandClientConnected, ClientID: 1}, *joined[0])
+	assert.Equal(t, Command{Type: CommandClientConnected, ClientID: 2}, *joined[1])
+
+	a.SendCommand(&Command{Type: "move", Tick: 4, ClientID: 2})
+	a.SendCommand(&Command{Type: CommandClientDisconnected})
+	cmds := receiveUntil(srv, 1)
+	b.SendCommand(&Command{Type: "jump"})
+	cmds = append(cmds, receiveUntil(srv, 1)...)
+	require.Len(t, cmds, 2)
+	assert.Equal(t, Command{Type: "move", Tick: 4, ClientID: 1}, *cmds[0], "Expected the server to overwrite the sent ClientID")
+	assert.Equal(t, Command{Type: "jump", ClientID: 2}, *cmds[1])
+
+	assert.Eventually(t, func() bool {
+		return a.(IdentifiedClient).ClientID() == 1 && b.(IdentifiedClient).ClientID() == 2
+	}, time.Second, time.Millisecond, "Expected clients to learn their IDs")
+
+	b.Close()
+	left := receiveUntil(srv, 1)
+	require.Len(t, left, 1)
+	assert.Equal(t, Command{Type: CommandClientDisconnected, ClientID: 2}, *left[0])
+	a.Close()
+}
+
+// pipeListener hands out the server ends of in-memory connections.
+type pipeListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+// dial connects a new client and returns its end.
+func (l *pipeListener) dial() net.Conn {
+	server, client := net.Pipe()
+	l.conns <- server
+	return client
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr { return &net.TCPAddr{} }
+
+func TestTCPServerTransportWelcomesWithoutBlocking(t *testing.T) {
+	ln := newPipeListener()
+	srv := serveTCP(ln, JSONCodec{})
+	defer srv.Close()
+
+	stuck := ln.dial() // never reads, so its welcome cannot be written
+	defer stuck.Close()
+	ready := ln.dial()
+	defer ready.Close()
+
+	data, err := readMsg(ready)
+	require.NoError(t, err)
+	welcome, _, err := JSONCodec{}.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, Command{Type: CommandClientConnected, ClientID: 2}, *welcome, "Expected a stuck client not to hold up the next")
+
+	joined := receiveUntil(srv, 1)
+	require.Len(t, joined, 1)
+	assert.Equal(t, ClientID(2), joined[0].ClientID, "Expected a client to join only once welcomed")
+
+	sent := make(chan struct{})
+	go func() {
+		srv.SendSnapshot(&Snapshot{Tick: 1})
+		close(sent)
+	}()
+	_, err = readMsg(ready)
+	require.NoError(t, err)
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("Expected snapshots to skip clients still being welcomed")
+	}
+}
@@ -16,6 +16,30 @@ type ServerTransport interface {
 	Close()
 }
 
+// ClientSnapshotSender is implemented by server transports that can address
+// a single client, such as [LocalTransport] and [TCPServerTransport].
+// [DeltaServerTransport] needs it to send each client its own delta.
+type ClientSnapshotSender interface {
+	// SendSnapshotTo delivers snapshot to the client with the given ID only.
+	// Does nothing if no such client is connected.
+	SendSnapshotTo(id ClientID, snapshot *Snapshot)
+
+	// CanSendTo reports whether SendSnapshotTo actually reaches clients.
+	// Wrappers such as DeltaServerTransport implement SendSnapshotTo
+	// whatever they wrap and report false when the wrapped transport cannot
+	// address clients.
+	CanSendTo() bool
+}
+
+// IdentifiedClient is implemented by client transports that know the
+// ClientID the server assigned them, such as the clients of [LocalTransport],
+// [TCPClientTransport] and [DeltaClientTransport].
+type IdentifiedClient interface {
+	// ClientID returns the ID the server assigned this client, or 0 while it
+	// is not yet known.
+	ClientID() ClientID
+}
+
 // ClientTransport is held by the render/Ebitengine side.
 // It sends player input commands and polls for the latest world snapshot.
 type ClientTransport interface {
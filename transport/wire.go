@@ -0,0 +1,83 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WireCodec encodes the messages the TCP transports exchange. Both ends of a
+// connection must use the same codec.
+//
+// [JSONCodec] is the default and the easiest to debug. [BinaryCodec] is
+// compact and round-trips registered concrete types.
+type WireCodec interface {
+	// EncodeCommand encodes a client-to-server command message.
+	EncodeCommand(cmd *Command) ([]byte, error)
+
+	// EncodeSnapshot encodes a server-to-client snapshot message.
+	EncodeSnapshot(snapshot *Snapshot) ([]byte, error)
+
+	// Decode decodes one message. Exactly one of the command and snapshot
+	// is non-nil when err is nil.
+	Decode(data []byte) (*Command, *Snapshot, error)
+}
+
+// JSONCodec is the [WireCodec] that encodes messages as JSON envelopes; see
+// the TCP wire format in the package docs.
+//
+// Payload and component values stored as bare interface{} decode as
+// map[string]interface{} or other generic JSON types on the remote side.
+type JSONCodec struct{}
+
+// tcpEnvelope is the JSON wire format wrapping all messages.
+// Kind routes the payload to the correct decode target on the receiver.
+type tcpEnvelope struct {
+	Kind    string          `json:"k"`
+	Payload json.RawMessage `json:"p"`
+}
+
+const (
+	tcpKindCommand  = "cmd"
+	tcpKindSnapshot = "snap"
+)
+
+func (JSONCodec) encode(kind string, v any) ([]byte, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&tcpEnvelope{Kind: kind, Payload: json.RawMessage(payload)})
+}
+
+// EncodeCommand encodes cmd as a "cmd" envelope.
+func (c JSONCodec) EncodeCommand(cmd *Command) ([]byte, error) {
+	return c.encode(tcpKindCommand, cmd)
+}
+
+// EncodeSnapshot encodes snapshot as a "snap" envelope.
+func (c JSONCodec) EncodeSnapshot(snapshot *Snapshot) ([]byte, error) {
+	return c.encode(tcpKindSnapshot, snapshot)
+}
+
+// Decode decodes a JSON envelope.
+func (JSONCodec) Decode(data []byte) (*Command, *Snapshot, error) {
+	var env tcpEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, nil, err
+	}
+	switch env.Kind {
+	case tcpKindCommand:
+		var cmd Command
+		if err := json.Unmarshal(env.Payload, &cmd); err != nil {
+			return nil, nil, err
+		}
+		return &cmd, nil, nil
+	case tcpKindSnapshot:
+		var snap Snapshot
+		if err := json.Unmarshal(env.Payload, &snap); err != nil {
+			return nil, nil, err
+		}
+		return nil, &snap, nil
+	}
+	return nil, nil, fmt.Errorf("transport: unknown message kind %q", env.Kind)
+}
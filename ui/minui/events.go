@@ -13,6 +13,7 @@ const (
 	EventTypeRadioGroupChange  event.EventType = "ui.radiogroup.change"
 	EventTypeTextInputChange   event.EventType = "ui.textinput.change"
 	EventTypeTextInputSubmit   event.EventType = "ui.textinput.submit"
+	EventTypeNumberInputChange event.EventType = "ui.numberinput.change"
 	EventTypeListBoxSelect     event.EventType = "ui.listbox.select"
 	EventTypeSelectBoxChange   event.EventType = "ui.selectbox.change"
 	EventTypeModalClose        event.EventType = "ui.modal.close"
@@ -0,0 +1,459 @@
+package minui
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/mechanical-lich/mlge/state"
+)
+
+// EntityInspector is a debug panel for an ecs.World. A TreeView on the left
+// lists the live entities grouped by blueprint; selecting one lists its
+// components' exported fields on the right, with a Toggle for bools, a
+// NumberInput for numbers and a TextInput for strings. Edits are written to
+// the live entity: pointer components are changed in place and marked
+// changed, value components are replaced with AddComponent, so OnChanged
+// observers fire either way. Other fields are shown read-only. The list and
+// the values refresh every RefreshEvery updates while the panel is shown.
+//
+// Wrap a state with the inspector to toggle it with Hotkey (F12 by default)
+// from that state and every state it leads to:
+//
+//	inspector := minui.NewEntityInspector("inspector", world, 640, 400)
+//	machine.PushState(inspector.Wrap(&PlayState{}))
+//
+// The inspector can also be added to a GUI like any element and shown with
+// Toggle. It starts hidden with the dark theme; SetTheme changes it.
+type EntityInspector struct {
+	*Panel
+
+	World *ecs.World
+
+	// Hotkey toggles the inspector from states returned by Wrap.
+	Hotkey ebiten.Key
+
+	// RefreshEvery is how many updates pass between refreshes of the entity
+	// list and field values. Defaults to 30.
+	RefreshEvery int
+
+	tree     *TreeView
+	fields   *ScrollPanel
+	rows     *VBox
+	editors  []inspectorField
+	selected ecs.EntityID
+	shown    []ecs.ComponentType // components of the selected entity listed in rows
+	listed   []*ecs.Entity       // entities in the tree, in tree order
+	frames   int
+}
+
+// inspectorField keeps one field editor in sync with the live value.
+type inspectorField struct {
+	element Element
+	refresh func(v reflect.Value)
+	t       ecs.ComponentType
+	path    []int
+}
+
+// NewEntityInspector creates a hidden inspector for world.
+func NewEntityInspector(id string, world *ecs.World, width, height int) *EntityInspector {
+	i := &EntityInspector{
+		Panel:        NewPanel(id),
+		World:        world,
+		Hotkey:       ebiten.KeyF12,
+		RefreshEvery: 30,
+	}
+	i.SetSize(width, height)
+	borderWidth := 1
+	i.style.BorderWidth = &borderWidth
+
+	padding := 6
+	treeWidth := width * 2 / 5
+	i.tree = NewTreeView(id+"_entities", treeWidth, height-2*padding)
+	i.tree.SetPosition(padding, padding)
+	i.tree.OnSelect = func(node *TreeNode) {
+		if entityID, ok := node.Data.(ecs.EntityID); ok {
+			i.Select(entityID)
+		}
+	}
+
+	i.fields = NewScrollPanel(id + "_fields")
+	i.fields.SetBounds(Rect{
+		X:      treeWidth + 2*padding,
+		Y:      padding,
+		Width:  width - treeWidth - 3*padding,
+		Height: height - 2*padding,
+	})
+
+	i.AddChild(i.tree)
+	i.AddChild(i.fields)
+	i.SetTheme(NewDarkTheme())
+	i.Select(ecs.NoEntity)
+	i.visible = false
+	return i
+}
+
+// GetType returns the element type
+func (i *EntityInspector) GetType() string {
+	return "EntityInspector"
+}
+
+// SetVisible shows or hides the inspector, refreshing it when shown
+func (i *EntityInspector) SetVisible(visible bool) {
+	if visible && !i.visible {
+		i.frames = 0
+		i.refresh()
+	}
+	i.Panel.SetVisible(visible)
+}
+
+// Toggle shows the inspector if hidden and hides it if shown
+func (i *EntityInspector) Toggle() {
+	i.SetVisible(!i.visible)
+}
+
+// Selected returns the entity being inspected, or ecs.NoEntity
+func (i *EntityInspector) Selected() ecs.EntityID {
+	return i.selected
+}
+
+// Select inspects the entity with the given ID
+func (i *EntityInspector) Select(id ecs.EntityID) {
+	i.selected = id
+	i.editors = nil
+	i.shown = nil
+	if i.rows != nil {
+		i.fields.RemoveChild(i.rows)
+	}
+	i.rows = NewVBox(i.GetID() + "_rows")
+	i.fields.AddChild(i.rows)
+	i.fields.ScrollTo(0)
+
+	e := i.entity()
+	if e == nil {
+		i.tree.SelectNode(nil)
+		text := "Select an entity"
+		if id != ecs.NoEntity {
+			text = fmt.Sprintf("%s despawned", id)
+		}
+		i.rows.AddChild(NewLabel(i.GetID()+"_empty", text))
+		return
+	}
+	i.tree.SelectByID(id.String())
+
+	title := fmt.Sprintf("%s %s", id, e.Blueprint)
+	if name := i.World.Name(id); name != "" {
+		title += " \"" + name + "\""
+	}
+	i.rows.AddChild(NewLabel(i.GetID()+"_title", title))
+
+	i.shown = componentTypes(e)
+	for _, t := range i.shown {
+		i.rows.AddChild(NewLabel(i.GetID()+"_"+string(t), "["+string(t)+"]"))
+		v := reflect.ValueOf(e.GetComponent(t))
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			i.addField(t, "value", nil, v)
+			continue
+		}
+		i.addStructFields(t, "", nil, v)
+	}
+}
+
+// addStructFields adds editors for the exported fields of struct v.
+func (i *EntityInspector) addStructFields(t ecs.ComponentType, prefix string, path []int, v reflect.Value) {
+	for n := 0; n < v.NumField(); n++ {
+		sf := v.Type().Field(n)
+		if !sf.IsExported() {
+			continue
+		}
+		fieldPath := append(slices.Clip(path), n)
+		fv := v.Field(n)
+		if fv.Kind() == reflect.Struct && hasExportedFields(fv.Type()) {
+			i.addStructFields(t, prefix+sf.Name+".", fieldPath, fv)
+			continue
+		}
+		i.addField(t, prefix+sf.Name, fieldPath, fv)
+	}
+}
+
+// addField adds a row with an editor for one field. A nil path is the
+// component value itself, which is only displayed.
+func (i *EntityInspector) addField(t ecs.ComponentType, name string, path []int, v reflect.Value) {
+	id := fmt.Sprintf("%s_%s_%s", i.GetID(), t, name)
+	label := NewLabel(id+"_label", name)
+	label.SetSize(140, 24)
+
+	field := inspectorField{t: t, path: path}
+	switch kind := v.Kind(); {
+	case path == nil:
+		field.element, field.refresh = readOnlyField(id)
+	case kind == reflect.Bool:
+		toggle := NewToggle(id, "")
+		toggle.OnChange = func(on bool) {
+			i.setField(t, path, func(f reflect.Value) { f.SetBool(on) })
+		}
+		field.element = toggle
+		field.refresh = func(f reflect.Value) { toggle.On = f.Bool() }
+	case kind >= reflect.Int && kind <= reflect.Float64:
+		input := NewNumberInput(id, 0)
+		input.Integer = kind < reflect.Float32
+		input.OnValueChange = func(value float64) {
+			i.setField(t, path, func(f reflect.Value) { setNumber(f, value) })
+		}
+		field.element = input
+		field.refresh = func(f reflect.Value) { input.SetValue(numberOf(f)) }
+	case kind == reflect.String:
+		input := NewTextInput(id, "")
+		input.OnSubmit = func(text string) {
+			i.setField(t, path, func(f reflect.Value) { f.SetString(text) })
+		}
+		field.element = input
+		field.refresh = func(f reflect.Value) { input.SetText(f.String()) }
+	default:
+		field.element, field.refresh = readOnlyField(id)
+	}
+	field.refresh(v)
+
+	row := NewHBox(id + "_row")
+	row.AddChild(label)
+	row.AddChild(field.element)
+	i.rows.AddChild(row)
+	i.editors = append(i.editors, field)
+}
+
+func readOnlyField(id string) (Element, func(reflect.Value)) {
+	label := NewLabel(id, "")
+	return label, func(f reflect.Value) { label.Text = fmt.Sprintf("%v", f.Interface()) }
+}
+
+// entity returns the inspected entity, or nil if it is not alive.
+func (i *EntityInspector) entity() *ecs.Entity {
+	if i.World == nil {
+		return nil
+	}
+	return i.World.Get(i.selected)
+}
+
+// fieldValue returns the live value of the field at path in component t.
+func (i *EntityInspector) fieldValue(e *ecs.Entity, t ecs.ComponentType, path []int) (reflect.Value, bool) {
+	v := reflect.ValueOf(e.GetComponent(t))
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if path == nil {
+		return v, v.IsValid()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return v.FieldByIndex(path), true
+}
+
+// setField writes to the field at path in component t of the inspected
+// entity. Pointer components are changed in place and marked changed; value
+// components are copied and replaced.
+func (i *EntityInspector) setField(t ecs.ComponentType, path []int, set func(reflect.Value)) {
+	e := i.entity()
+	if e == nil {
+		return
+	}
+	v := reflect.ValueOf(e.GetComponent(t))
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		set(v.Elem().FieldByIndex(path))
+		e.MarkChanged(t)
+		return
+	}
+	if !v.IsValid() {
+		return
+	}
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	set(copied.FieldByIndex(path))
+	e.AddComponent(copied.Interface().(ecs.Component))
+}
+
+// Update refreshes the entity list and values every RefreshEvery updates
+func (i *EntityInspector) Update() {
+	if !i.visible {
+		return
+	}
+	i.frames++
+	every := i.RefreshEvery
+	if every <= 0 {
+		every = 30
+	}
+	if i.frames >= every {
+		i.frames = 0
+		i.refresh()
+	}
+	i.Panel.Update()
+}
+
+func (i *EntityInspector) refresh() {
+	i.refreshEntities()
+
+	e := i.entity()
+	if e == nil {
+		if i.shown != nil {
+			i.Select(i.selected)
+		}
+		return
+	}
+	if !slices.Equal(i.shown, componentTypes(e)) {
+		i.Select(i.selected)
+		return
+	}
+	for _, field := range i.editors {
+		if field.element.IsFocused() {
+			continue
+		}
+		if v, ok := i.fieldValue(e, field.t, field.path); ok {
+			field.refresh(v)
+		}
+	}
+}
+
+// refreshEntities rebuilds the tree when the set of live entities changed,
+// keeping expanded groups and the selection.
+func (i *EntityInspector) refreshEntities() {
+	var entities []*ecs.Entity
+	if i.World != nil {
+		entities = slices.Clone(i.World.Entities())
+	}
+	slices.SortFunc(entities, func(a, b *ecs.Entity) int {
+		if c := cmp.Compare(a.Blueprint, b.Blueprint); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if slices.Equal(entities, i.listed) {
+		return
+	}
+	i.listed = entities
+
+	expanded := make(map[string]bool)
+	for _, root := range i.tree.Roots {
+		expanded[root.ID] = root.Expanded
+	}
+	i.tree.Clear()
+	var group *TreeNode
+	for _, e := range entities {
+		blueprint := e.Blueprint
+		if blueprint == "" {
+			blueprint = "(no blueprint)"
+		}
+		if group == nil || group.Data != blueprint {
+			group = NewTreeNode("blueprint:"+blueprint, blueprint)
+			group.Data = blueprint
+			group.Expanded = expanded[group.ID]
+			i.tree.AddRoot(group)
+		}
+		text := e.ID.String()
+		if name := i.World.Name(e.ID); name != "" {
+			text += " " + name
+		}
+		node := NewTreeNode(e.ID.String(), text)
+		node.Data = e.ID
+		group.AddChild(node)
+	}
+	for _, root := range i.tree.Roots {
+		root.Text = fmt.Sprintf("%s (%d)", root.Data, len(root.Children))
+	}
+	i.tree.updateVisibleNodes()
+	i.tree.SelectByID(i.selected.String())
+}
+
+// Wrap returns s with the inspector layered on top: Hotkey toggles the
+// inspector, which is updated and drawn after s while shown. States s
+// returns from Update are wrapped too, so the hotkey works from any state.
+func (i *EntityInspector) Wrap(s state.StateInterface) state.StateInterface {
+	if s == nil {
+		return nil
+	}
+	if wrapped, ok := s.(*inspectedState); ok && wrapped.inspector == i {
+		return s
+	}
+	return &inspectedState{StateInterface: s, inspector: i}
+}
+
+type inspectedState struct {
+	state.StateInterface
+	inspector *EntityInspector
+}
+
+func (s *inspectedState) Update() state.StateInterface {
+	if inpututil.IsKeyJustPressed(s.inspector.Hotkey) {
+		s.inspector.Toggle()
+	}
+	if s.inspector.IsVisible() {
+		s.inspector.Layout()
+		s.inspector.Update()
+	}
+	return s.inspector.Wrap(s.StateInterface.Update())
+}
+
+func (s *inspectedState) Draw(screen *ebiten.Image) {
+	s.StateInterface.Draw(screen)
+	s.inspector.Draw(screen)
+}
+
+func componentTypes(e *ecs.Entity) []ecs.ComponentType {
+	types := make([]ecs.ComponentType, 0, len(e.Components))
+	for t := range e.Components {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for n := 0; n < t.NumField(); n++ {
+		if t.Field(n).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func numberOf(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+// setNumber stores value in the numeric field v, ignoring values the field
+// cannot hold.
+func setNumber(v reflect.Value, value float64) {
+	switch {
+	case v.CanInt():
+		if n := int64(value); float64(n) == value && !v.OverflowInt(n) {
+			v.SetInt(n)
+		}
+	case v.CanUint():
+		if n := uint64(value); value >= 0 && float64(n) == value && !v.OverflowUint(n) {
+			v.SetUint(n)
+		}
+	case v.CanFloat():
+		if !v.OverflowFloat(value) && !math.IsInf(value, 0) {
+			v.SetFloat(value)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package minui
+
+import (
+	"testing"
+
+	"github.com/mechanical-lich/mlge/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type inspectedStats struct {
+	Health int
+	Speed  float64
+	Alive  bool
+	Name   string
+	Offset struct{ X, Y float64 }
+	Loot   []string
+}
+
+func (c *inspectedStats) GetType() ecs.ComponentType { return "Stats" }
+
+type inspectedMarker struct{ Level int }
+
+func (c inspectedMarker) GetType() ecs.ComponentType { return "Marker" }
+
+func TestEntityInspector_GroupsEntitiesByBlueprint(t *testing.T) {
+	w := ecs.NewWorld()
+	goblin := &ecs.Entity{Blueprint: "goblin"}
+	w.Add(goblin)
+	w.Add(&ecs.Entity{Blueprint: "goblin"})
+	w.Add(&ecs.Entity{Blueprint: "arrow"})
+
+	insp := NewEntityInspector("insp", w, 600, 400)
+	insp.SetVisible(true)
+
+	if assert.Len(t, insp.tree.Roots, 2) {
+		assert.Equal(t, "arrow (1)", insp.tree.Roots[0].Text)
+		assert.Equal(t, "goblin (2)", insp.tree.Roots[1].Text)
+		assert.Equal(t, goblin.ID, insp.tree.Roots[1].Children[0].Data)
+	}
+}
+
+func TestEntityInspector_EditsWriteToLiveEntity(t *testing.T) {
+	w := ecs.NewWorld()
+	e := w.Spawn()
+	stats := &inspectedStats{Health: 10, Loot: []string{"gold"}}
+	e.AddComponent(stats)
+	e.AddComponent(inspectedMarker{Level: 1})
+
+	var changed []ecs.ComponentType
+	w.OnChanged("Stats", func(_ *ecs.Entity, c ecs.Component) { changed = append(changed, c.GetType()) })
+	w.OnChanged("Marker", func(_ *ecs.Entity, c ecs.Component) { changed = append(changed, c.GetType()) })
+
+	insp := NewEntityInspector("insp", w, 600, 400)
+	insp.Select(e.ID)
+
+	editors := map[string]Element{}
+	for _, f := range insp.editors {
+		editors[f.element.GetID()] = f.element
+	}
+	health := editors["insp_Stats_Health"].(*NumberInput)
+	assert.True(t, health.Integer)
+	assert.Equal(t, 10.0, health.Value)
+	assert.IsType(t, &Toggle{}, editors["insp_Stats_Alive"])
+	assert.IsType(t, &TextInput{}, editors["insp_Stats_Name"])
+	assert.IsType(t, &NumberInput{}, editors["insp_Stats_Offset.X"])
+	assert.IsType(t, &Label{}, editors["insp_Stats_Loot"], "Expected slices to be read-only")
+
+	health.OnValueChange(25)
+	editors["insp_Stats_Alive"].(*Toggle).OnChange(true)
+	editors["insp_Stats_Offset.X"].(*NumberInput).OnValueChange(1.5)
+	assert.Equal(t, 25, stats.Health)
+	assert.True(t, stats.Alive)
+	assert.Equal(t, 1.5, stats.Offset.X)
+
+	editors["insp_Marker_Level"].(*NumberInput).OnValueChange(3)
+	assert.Equal(t, inspectedMarker{Level: 3}, e.GetComponent("Marker"), "Expected value components to be replaced")
+	assert.Equal(t, []ecs.ComponentType{"Stats", "Stats", "Stats", "Marker"}, changed)
+
+	stats.Health = 40
+	insp.refresh()
+	assert.Equal(t, 40.0, health.Value, "Expected values to refresh from the entity")
+
+	w.Despawn(e.ID)
+	insp.refresh()
+	assert.Empty(t, insp.editors)
+}
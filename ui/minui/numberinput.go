@@ -0,0 +1,87 @@
+package minui
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mechanical-lich/mlge/event"
+)
+
+// NumberInputChangeEvent is fired when a number input commits a new value
+type NumberInputChangeEvent struct {
+	InputID  string
+	Input    *NumberInput
+	Value    float64
+	OldValue float64
+}
+
+func (e NumberInputChangeEvent) GetType() event.EventType {
+	return EventTypeNumberInputChange
+}
+
+// NumberInput is a TextInput holding a number. The typed text is committed
+// when the input loses focus or Enter is pressed; text that does not parse
+// (or is fractional when Integer is set) reverts to the previous value.
+type NumberInput struct {
+	*TextInput
+	Value         float64
+	Integer       bool
+	OnValueChange func(value float64)
+}
+
+// NewNumberInput creates a new number input showing value
+func NewNumberInput(id string, value float64) *NumberInput {
+	n := &NumberInput{
+		TextInput: NewTextInput(id, ""),
+	}
+	n.SetSize(100, 28)
+	n.SetValue(value)
+	return n
+}
+
+// GetType returns the element type
+func (n *NumberInput) GetType() string {
+	return "NumberInput"
+}
+
+// SetValue sets the value and its text without firing OnValueChange
+func (n *NumberInput) SetValue(value float64) {
+	n.Value = value
+	n.SetText(n.format(value))
+}
+
+// Update updates the input and commits the text when focus is lost
+func (n *NumberInput) Update() {
+	wasFocused := n.IsFocused()
+	n.TextInput.Update()
+	if wasFocused && !n.IsFocused() {
+		n.commit()
+	}
+}
+
+func (n *NumberInput) commit() {
+	value, err := strconv.ParseFloat(strings.TrimSpace(n.Text), 64)
+	if err != nil || math.IsNaN(value) || (n.Integer && value != math.Trunc(value)) {
+		n.SetText(n.format(n.Value))
+		return
+	}
+	old := n.Value
+	n.SetValue(value)
+	if value == old {
+		return
+	}
+	if n.OnValueChange != nil {
+		n.OnValueChange(value)
+	}
+	event.GetQueuedInstance().QueueEvent(NumberInputChangeEvent{
+		InputID:  n.GetID(),
+		Input:    n,
+		Value:    value,
+		OldValue: old,
+	})
+}
+
+func (n *NumberInput) format(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,84 @@
+package minui
+
+import (
+	"github.com/mechanical-lich/mlge/ecs"
+)
+
+// ProfilerPanel is a debug overlay that shows an ecs.Profiler's report: tick
+// time against the budget, then the slowest systems with their global and
+// per-entity times and entity counts. The text turns to the theme's Error
+// color while the latest tick is over budget.
+//
+//	prof := ecs.NewProfiler(0)
+//	server.SetProfiler(prof)
+//	gui.AddElement(minui.NewProfilerPanel("profiler", prof))
+type ProfilerPanel struct {
+	*Label
+
+	Profiler *ecs.Profiler
+
+	// MaxSystems limits how many systems are listed; 0 lists all.
+	MaxSystems int
+
+	// RefreshEvery is how many updates pass between text refreshes, so the
+	// numbers stay readable. Defaults to 30.
+	RefreshEvery int
+
+	frames int
+}
+
+// NewProfilerPanel creates a panel showing p's report.
+func NewProfilerPanel(id string, p *ecs.Profiler) *ProfilerPanel {
+	panel := &ProfilerPanel{
+		Label:        NewLabel(id, ""),
+		Profiler:     p,
+		MaxSystems:   10,
+		RefreshEvery: 30,
+	}
+	fontSize := 12
+	padding := NewEdgeInsets(6)
+	style := panel.GetStyle()
+	style.FontSize = &fontSize
+	style.Padding = padding
+	panel.refresh()
+	return panel
+}
+
+// GetType returns the element type
+func (p *ProfilerPanel) GetType() string {
+	return "ProfilerPanel"
+}
+
+// Update refreshes the report text every RefreshEvery updates.
+func (p *ProfilerPanel) Update() {
+	if !p.visible {
+		return
+	}
+	p.frames++
+	every := p.RefreshEvery
+	if every <= 0 {
+		every = 30
+	}
+	if p.frames >= every {
+		p.frames = 0
+		p.refresh()
+	}
+	p.Label.Update()
+}
+
+func (p *ProfilerPanel) refresh() {
+	if p.Profiler == nil {
+		p.Text = "profiler: off"
+		return
+	}
+	report := p.Profiler.Report()
+	p.Text = report.Format(p.MaxSystems)
+
+	p.style.ForegroundColor = nil
+	if report.Budget > 0 && report.Tick.Last > report.Budget {
+		if theme := p.GetTheme(); theme != nil {
+			col := theme.Colors.Error
+			p.style.ForegroundColor = &col
+		}
+	}
+}